@@ -60,3 +60,10 @@ Create the name of the service account to use
 {{- default "default" .Values.serviceAccount.name }}
 {{- end }}
 {{- end }}
+
+{{/*
+Create the name of the secret holding the management token
+*/}}
+{{- define "app.managementTokenSecretName" -}}
+{{- default (printf "%s-management" (include "app.fullname" .)) .Values.management.tokenSecret.name }}
+{{- end }}
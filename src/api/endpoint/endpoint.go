@@ -0,0 +1,78 @@
+package endpoint
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/coderollers/go-logger"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"my-microservice/api/response"
+	"my-microservice/api/validation"
+	"my-microservice/apperrors"
+	"my-microservice/configuration"
+	"my-microservice/tracer"
+)
+
+// Handler is a typed HTTP handler. The context carries the correlation ID, the
+// request deadline and the span of the handler. Errors are rendered through
+// the error catalog, so handlers should return apperrors.
+type Handler[Req, Resp any] func(ctx context.Context, req Req) (Resp, error)
+
+// Handle adapts a typed handler to Gin. The request is bound and validated with
+// validation.Bind, the handler runs in a span named `name`, and its result is
+// rendered with SuccessResponse or ErrorResponse. Requests are tracked as tasks
+// by the Tasks middleware. `Req` can be a struct or a pointer to a struct, such
+// as a proto message; use struct{} for handlers without input.
+//
+// The Swagger annotations go on the typed handler, with the request and
+// response types:
+//
+//	// @Param request body GreetingRequest true "The greeting to create"
+//	// @Success 200 {object} models.JSONSuccessResult[v1.GreetingResponse]
+func Handle[Req, Resp any](name string, fn Handler[Req, Resp]) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		correlationId := c.MustGet("correlation_id").(string)
+		ctx := context.WithValue(c.Request.Context(), configuration.CorrelationIdKey, correlationId)
+		ctx, span := tracer.Tracer.Start(ctx, name, trace.WithAttributes(attribute.String("CorrelationId", correlationId)))
+		defer span.End()
+
+		req, target := newRequest[Req]()
+		if err := validation.Bind(c, target); err != nil {
+			fail(ctx, c, span, name, err)
+			return
+		}
+
+		resp, err := fn(ctx, *req)
+		if err != nil {
+			fail(ctx, c, span, name, err)
+			return
+		}
+		response.SuccessResponse(c, resp)
+	}
+}
+
+// newRequest allocates the request, and the struct it points to if `Req` is a
+// pointer. The target is what validation.Bind fills.
+func newRequest[Req any]() (req *Req, target interface{}) {
+	req = new(Req)
+	if t := reflect.TypeOf(req).Elem(); t.Kind() == reflect.Pointer {
+		reflect.ValueOf(req).Elem().Set(reflect.New(t.Elem()))
+		return req, *req
+	}
+	return req, req
+}
+
+func fail(ctx context.Context, c *gin.Context, span trace.Span, name string, err error) {
+	appErr := apperrors.From(err)
+	if appErr.HttpStatus >= 500 {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, appErr.Code)
+		log := logger.SugaredLogger().WithContextCorrelationId(ctx).With("package", "endpoint")
+		log.Errorw("Request failed with an internal error", "handler", name, "error", err.Error())
+	}
+	response.ErrorResponse(c, nil, err)
+}
@@ -0,0 +1,98 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/coderollers/go-logger"
+
+	"my-microservice/api"
+	"my-microservice/api/models"
+	"my-microservice/configuration"
+)
+
+const (
+	correlationId = "705e4dcb-3ecd-24f3-3a35-3e926e4bded5"
+	maxBodyBytes  = 64
+)
+
+func TestMain(m *testing.M) {
+	logger.Init(context.Background(), false, false)
+	configuration.AppConfig().HttpServer.MaxBodyBytes = maxBodyBytes
+	os.Exit(m.Run())
+}
+
+// TestFailureEnvelope checks that the failures produced outside the handlers
+// share the JSON envelope of the handler failures, with the correlation ID
+func TestFailureEnvelope(t *testing.T) {
+	router := api.SetupGin()
+	largeBody := `{"name":"` + strings.Repeat("x", maxBodyBytes) + `"}`
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		body          string
+		contentLength int64
+		wantCode      int
+		wantErrorCode string
+		wantAllow     string
+	}{
+		{name: "unknown route", method: http.MethodGet, path: "/v1/unknown", wantCode: http.StatusNotFound},
+		{name: "unsupported method", method: http.MethodDelete, path: "/v1/greetings", wantCode: http.StatusMethodNotAllowed, wantAllow: "POST"},
+		{name: "announced body too large", method: http.MethodPost, path: "/v1/greetings", body: largeBody, wantCode: http.StatusRequestEntityTooLarge, wantErrorCode: "PAYLOAD_TOO_LARGE"},
+		{name: "streamed body too large", method: http.MethodPost, path: "/v1/greetings", body: largeBody, contentLength: -1, wantCode: http.StatusRequestEntityTooLarge, wantErrorCode: "PAYLOAD_TOO_LARGE"},
+		{name: "malformed body", method: http.MethodPost, path: "/v1/greetings", body: `{"name":`, wantCode: http.StatusBadRequest, wantErrorCode: "INVALID_ARGUMENT"},
+		{name: "invalid body", method: http.MethodPost, path: "/v1/greetings", body: `{}`, wantCode: http.StatusBadRequest, wantErrorCode: "INVALID_ARGUMENT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req := httptest.NewRequest(tt.method, tt.path, body)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Correlation-ID", correlationId)
+			if tt.contentLength != 0 {
+				req.ContentLength = tt.contentLength
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d, body: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+			if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+				t.Errorf("Content-Type = %q, want application/json", contentType)
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", allow, tt.wantAllow)
+			}
+
+			var result models.JSONFailureResult
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Fatalf("body is not a JSON envelope: %s, body: %s", err.Error(), w.Body.String())
+			}
+			if result.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d", result.Code, tt.wantCode)
+			}
+			// JSONNotFoundResult, the 404 envelope, has no message
+			if result.Message == "" && tt.wantCode != http.StatusNotFound {
+				t.Error("message is empty")
+			}
+			if result.CorrelationId != correlationId {
+				t.Errorf("correlation_id = %q, want %q", result.CorrelationId, correlationId)
+			}
+			if tt.wantErrorCode != "" && result.ErrorCode != tt.wantErrorCode {
+				t.Errorf("error_code = %q, want %q", result.ErrorCode, tt.wantErrorCode)
+			}
+		})
+	}
+}
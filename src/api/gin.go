@@ -2,21 +2,21 @@ package api
 
 import (
 	"github.com/coderollers/go-logger"
-	"github.com/coderollers/go-stats/concurrency"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
+	"my-microservice/api/endpoint"
+	fallbackHandlers "my-microservice/api/handlers/fallback"
+	healthHandlers "my-microservice/api/handlers/health"
 	handlersV1 "my-microservice/api/handlers/v1"
 	"my-microservice/api/middleware"
 	"my-microservice/configuration"
+	"my-microservice/limiter"
 )
 
 func SetupGin() *gin.Engine {
-	concurrency.GlobalWaitGroup.Add(1)
-	defer concurrency.GlobalWaitGroup.Done()
-
 	conf := configuration.AppConfig()
 	log := logger.SugaredLogger()
 
@@ -26,22 +26,49 @@ func SetupGin() *gin.Engine {
 		gin.SetMode(gin.ReleaseMode)
 	}
 	router := gin.New()
+	router.HandleMethodNotAllowed = true
 
 	// Set up the middleware
 	if conf.GinLogger {
 		log.Warnf("Gin's logger is active! Logs will be unstructured!")
 		router.Use(gin.Logger())
 	}
-	router.Use(gin.Recovery())
 	router.Use(middleware.CorrelationId())
+	router.Use(middleware.Recovery())
 	router.Use(otelgin.Middleware(configuration.OTName))
+	router.Use(middleware.TracePanics())
+	if conf.AccessLog.Enabled {
+		router.Use(middleware.AccessLog())
+	}
+	router.Use(middleware.Maintenance())
+	if conf.Limiter.Enabled {
+		router.Use(middleware.LoadShedding(limiter.New("http"), conf.Limiter.CriticalPaths))
+	}
+	router.Use(middleware.Tasks())
+	router.Use(middleware.Timeout())
+	router.Use(middleware.MaxBodySize())
 	// TEMPLATE: Add more middleware
 
+	router.NoRoute(fallbackHandlers.NoRoute)
+	router.NoMethod(fallbackHandlers.NoMethod(router.Routes))
+
+	// The probes are served by the management listener, unless it is disabled
+	if conf.Management.Port == 0 {
+		router.GET("/healthz", healthHandlers.LivenessGet)
+		router.GET("/readyz", healthHandlers.ReadinessGet)
+	}
+
 	userAPI := router.Group("/v1")
 	{
 		userAPI.GET("/", handlersV1.IndexGet)
+		userAPI.POST("/greetings", endpoint.Handle("GreetingPost", handlersV1.GreetingPost))
+		userAPI.POST("/greetings/async", handlersV1.GreetingAsyncPost)
+		userAPI.GET("/jobs", handlersV1.JobsGet)
+		userAPI.GET("/jobs/:id", endpoint.Handle("JobGet", handlersV1.JobGet))
+		userAPI.POST("/jobs/:id/cancel", endpoint.Handle("JobCancelPost", handlersV1.JobCancelPost))
 		// TEMPLATE: Add more handlers
 	}
+
 	// Activate swagger if configured
 	if conf.UseSwagger {
 		log.Infof("Swagger is active, enabling endpoints")
@@ -3,79 +3,147 @@ package api
 import (
 	"context"
 	"fmt"
+	"math"
 	"net"
+	"time"
 
 	"github.com/coderollers/go-logger"
-	"github.com/coderollers/go-stats/concurrency"
 	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 
 	grpcServices "my-microservice/api/grpc"
+	"my-microservice/api/interceptors"
 	"my-microservice/configuration"
+	"my-microservice/health"
+	"my-microservice/lifecycle"
+	"my-microservice/limiter"
 	"my-microservice/protos"
 )
 
-func StartGrpc(ctx context.Context) (*grpc.Server, *grpcweb.WrappedGrpcServer) {
-	concurrency.GlobalWaitGroup.Add(1)
-	defer concurrency.GlobalWaitGroup.Done()
-
+// SetupGrpc creates the GRPC server and registers the GRPC services. If the HTTP
+// and GRPC ports match, a GRPC-Web wrapper is returned as well, to be served by
+// the HTTP server. Otherwise, GrpcComponent serves the native GRPC endpoint.
+func SetupGrpc() (*grpc.Server, *grpcweb.WrappedGrpcServer) {
 	conf := configuration.AppConfig()
 	log := logger.SugaredLogger()
 
 	// Set up grpc
 	log.Debugf("Setting up GRPC")
-	grpcServer := grpc.NewServer()
+	// The tracing interceptors come before the recovery, so that the recovered
+	// panics are recorded on the span of the call
+	unaryInterceptors := []grpc.UnaryServerInterceptor{interceptors.CorrelationIdUnary(), otelgrpc.UnaryServerInterceptor(), interceptors.RecoveryUnary()}
+	streamInterceptors := []grpc.StreamServerInterceptor{interceptors.CorrelationIdStream(), otelgrpc.StreamServerInterceptor(), interceptors.RecoveryStream()}
+	if conf.AccessLog.Enabled {
+		unaryInterceptors = append(unaryInterceptors, interceptors.AccessLogUnary())
+		streamInterceptors = append(streamInterceptors, interceptors.AccessLogStream())
+	}
+	unaryInterceptors = append(unaryInterceptors, interceptors.ErrorsUnary(), interceptors.MaintenanceUnary(conf.Maintenance.AllowedMethods))
+	streamInterceptors = append(streamInterceptors, interceptors.ErrorsStream(), interceptors.MaintenanceStream(conf.Maintenance.AllowedMethods))
+	if conf.Limiter.Enabled {
+		grpcLimiter := limiter.New("grpc")
+		unaryInterceptors = append(unaryInterceptors, interceptors.LoadSheddingUnary(grpcLimiter, conf.Limiter.CriticalMethods))
+		streamInterceptors = append(streamInterceptors, interceptors.LoadSheddingStream(grpcLimiter, conf.Limiter.CriticalMethods))
+	}
+	unaryInterceptors = append(unaryInterceptors, interceptors.TasksUnary(), interceptors.TimeoutUnary(), interceptors.ValidationUnary())
+	streamInterceptors = append(streamInterceptors, interceptors.TasksStream(), interceptors.TimeoutStream(), interceptors.ValidationStream())
+	// TEMPLATE: Add more interceptors
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+		grpc.MaxRecvMsgSize(int(conf.Grpc.MaxRecvMsgBytes)),
+		grpc.MaxSendMsgSize(int(conf.Grpc.MaxSendMsgBytes)),
+		grpc.MaxConcurrentStreams(uint32(conf.Grpc.MaxConcurrentStreams)),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     secondsOrInfinity(conf.Grpc.MaxConnectionIdleSec),
+			MaxConnectionAge:      secondsOrInfinity(conf.Grpc.MaxConnectionAgeSec),
+			MaxConnectionAgeGrace: secondsOrInfinity(conf.Grpc.MaxConnectionAgeGraceSec),
+			Time:                  time.Duration(conf.Grpc.KeepaliveTimeSec) * time.Second,
+			Timeout:               time.Duration(conf.Grpc.KeepaliveTimeoutSec) * time.Second,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             time.Duration(conf.Grpc.KeepaliveMinTimeSec) * time.Second,
+			PermitWithoutStream: conf.Grpc.KeepalivePermitWithoutStream,
+		}),
+		grpc.ConnectionTimeout(time.Duration(conf.Grpc.ConnectionTimeoutSec)*time.Second),
+		grpc.WriteBufferSize(int(conf.Grpc.WriteBufferBytes)),
+		grpc.ReadBufferSize(int(conf.Grpc.ReadBufferBytes)),
+	)
+
+	// Standard GRPC health service, reporting readiness
+	healthpb.RegisterHealthServer(grpcServer, health.GrpcServer())
 
 	// Example GRPC service
 	protos.RegisterGreeterServer(grpcServer, &grpcServices.GreeterService{})
+	// Long-running operations, backed by the jobs
+	protos.RegisterOperationsServer(grpcServer, &grpcServices.OperationsService{})
 	// TEMPLATE: Register GRPC services
 
 	if conf.HttpPort == conf.GrpcPort {
 		// Ports match, we return a GRPC-Web wrapper to use with our regular HTTP listener
 		log.Infof("Multiplexed GRPC native and GRPC-Web mode on :%d", conf.GrpcPort)
+		log.Infof("GRPC transport settings are ignored in multiplexed mode, the HTTP server profile applies instead")
 		return grpcServer, grpcweb.WrapServer(grpcServer)
 	}
 
-	// Start native GRPC endpoint if ports differ
-	go nativeGrpc(ctx, grpcServer)
-
-	return nil, nil
+	return grpcServer, nil
 }
 
-func nativeGrpc(ctx context.Context, grpcServer *grpc.Server) {
-	concurrency.GlobalWaitGroup.Add(1)
-	defer concurrency.GlobalWaitGroup.Done()
-
+// GrpcComponent serves `grpcServer` on the native GRPC port. On shutdown, it
+// sends GOAWAY to clients and waits for in-flight RPCs until the stop deadline,
+// after which the remaining RPCs are cancelled.
+func GrpcComponent(grpcServer *grpc.Server) lifecycle.Component {
 	conf := configuration.AppConfig()
 	log := logger.SugaredLogger()
 
-	// Native GRPC endpoint
-	log.Infof("GRPC native mode on :%d", conf.GrpcPort)
-
-	var (
-		listener net.Listener
-		err      error
-	)
-
-	// Set up the listener
-	if listener, err = net.Listen("tcp", fmt.Sprintf(":%d", conf.GrpcPort)); err != nil {
-		log.Fatalf("Cannot open listener socket: %s", err.Error())
+	return lifecycle.Component{
+		Name:      "grpc-server",
+		DependsOn: []string{"telemetry"},
+		Start: func(ctx context.Context) error {
+			// Native GRPC endpoint
+			log.Infof("GRPC native mode on :%d", conf.GrpcPort)
+
+			// Set up the listener
+			listener, err := net.Listen("tcp", fmt.Sprintf(":%d", conf.GrpcPort))
+			if err != nil {
+				return fmt.Errorf("cannot open listener socket: %w", err)
+			}
+
+			// Start the GRPC Server
+			go func() {
+				if err := grpcServer.Serve(listener); err != nil {
+					log.Fatalf("Failed to serve GRPC endpoint: %s", err.Error())
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			log.Infof("Attempting to shutdown the GRPC server")
+			stopped := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+				log.Infof("GRPC Server was shutdown successfully")
+				return nil
+			case <-ctx.Done():
+				grpcServer.Stop()
+				<-stopped
+				return fmt.Errorf("in-flight RPCs were cancelled: %w", ctx.Err())
+			}
+		},
 	}
+}
 
-	// Start the HTTP Server
-	go func() {
-		concurrency.GlobalWaitGroup.Add(1)
-		defer concurrency.GlobalWaitGroup.Done()
-
-		if err = grpcServer.Serve(listener); err != nil {
-			log.Fatalf("Failed to serve GRPC endpoint: %s", err.Error())
-		}
-	}()
-
-	// Block until SIGTERM/SIGINT
-	<-ctx.Done()
-
-	// Clean up and shutdown the HTTP server
-	grpcServer.Stop()
-	log.Infof("GRPC Server was shutdown")
+// secondsOrInfinity converts a number of seconds into a duration, where 0 means
+// the setting is disabled
+func secondsOrInfinity(seconds int32) time.Duration {
+	if seconds == 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(seconds) * time.Second
 }
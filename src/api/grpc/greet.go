@@ -3,7 +3,9 @@ package grpc
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"my-microservice/jobs"
 	"my-microservice/protos"
 )
 
@@ -12,7 +14,29 @@ type GreeterService struct {
 }
 
 func (g *GreeterService) SayHello(ctx context.Context, request *protos.HelloRequest) (*protos.HelloReply, error) {
+	// The request was validated against the rules of greet.proto by the Validation interceptors.
+	// Errors from the catalog are rendered with their GRPC code and details, for example:
+	// return nil, apperrors.ErrNotFound.WithMetadata("name", request.Name)
 	return &protos.HelloReply{
 		Message: fmt.Sprintf("Hello there, %s", request.Name),
 	}, nil
 }
+
+func (g *GreeterService) SayHelloAsync(ctx context.Context, request *protos.HelloRequest) (*protos.Operation, error) {
+	// The job outlives the RPC, so it must not use anything bound to it
+	job, err := jobs.Submit(ctx, "SayHelloAsync", func(ctx context.Context, progress func(percent int)) (interface{}, error) {
+		for step := 1; step <= 5; step++ {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Second):
+				progress(step * 20)
+			}
+		}
+		return &protos.HelloReply{Message: fmt.Sprintf("Hello there, %s", request.Name)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewOperation(job)
+}
@@ -0,0 +1,69 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"my-microservice/configuration"
+	"my-microservice/logging"
+	"my-microservice/protos"
+)
+
+// LoggingService is the GRPC counterpart of the log level management endpoints
+type LoggingService struct {
+	protos.UnimplementedLoggingServer
+}
+
+func (l *LoggingService) GetLogLevels(ctx context.Context, request *protos.GetLogLevelsRequest) (*protos.LogLevels, error) {
+	return logLevelsToProto(logging.GetLevels()), nil
+}
+
+func (l *LoggingService) SetLogLevel(ctx context.Context, request *protos.SetLogLevelRequest) (*protos.LogLevels, error) {
+	level, err := logging.ParseLevel(request.Level)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	logging.SetLevel(request.Logger, level, time.Duration(request.TtlSeconds)*time.Second, callerFromContext(ctx))
+	return logLevelsToProto(logging.GetLevels()), nil
+}
+
+func (l *LoggingService) ResetLogLevel(ctx context.Context, request *protos.ResetLogLevelRequest) (*protos.LogLevels, error) {
+	logging.ResetLevel(request.Logger, callerFromContext(ctx))
+	return logLevelsToProto(logging.GetLevels()), nil
+}
+
+func callerFromContext(ctx context.Context) logging.Caller {
+	caller := logging.Caller{Protocol: "grpc"}
+	if p, ok := peer.FromContext(ctx); ok {
+		caller.Address = p.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("user-agent"); len(values) > 0 {
+			caller.UserAgent = values[0]
+		}
+	}
+	caller.CorrelationId, _ = ctx.Value(configuration.CorrelationIdKey).(string)
+	return caller
+}
+
+func logLevelsToProto(levels logging.Levels) *protos.LogLevels {
+	result := &protos.LogLevels{Global: logLevelToProto(levels.Global)}
+	for _, state := range levels.Loggers {
+		result.Loggers = append(result.Loggers, logLevelToProto(state))
+	}
+	return result
+}
+
+func logLevelToProto(state logging.LevelState) *protos.LogLevel {
+	result := &protos.LogLevel{Logger: state.Logger, Level: state.Level}
+	if state.ExpiresAt != nil {
+		result.ExpiresAt = timestamppb.New(*state.ExpiresAt)
+	}
+	return result
+}
@@ -0,0 +1,26 @@
+package grpc
+
+import (
+	"context"
+
+	"my-microservice/health"
+	"my-microservice/logging"
+	"my-microservice/protos"
+)
+
+// MaintenanceService is the GRPC counterpart of the maintenance mode management
+// endpoints
+type MaintenanceService struct {
+	protos.UnimplementedMaintenanceServer
+}
+
+func (m *MaintenanceService) GetMaintenance(ctx context.Context, request *protos.GetMaintenanceRequest) (*protos.MaintenanceState, error) {
+	return &protos.MaintenanceState{Enabled: health.Maintenance()}, nil
+}
+
+func (m *MaintenanceService) SetMaintenance(ctx context.Context, request *protos.SetMaintenanceRequest) (*protos.MaintenanceState, error) {
+	previous := health.Maintenance()
+	health.SetMaintenance(request.Enabled)
+	logging.Audit("Maintenance mode changed", callerFromContext(ctx), "previous_enabled", previous, "enabled", request.Enabled)
+	return &protos.MaintenanceState{Enabled: health.Maintenance()}, nil
+}
@@ -0,0 +1,210 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"my-microservice/api/pagination"
+	"my-microservice/apperrors"
+	"my-microservice/jobs"
+	"my-microservice/protos"
+)
+
+// operationPrefix starts the names of the operations, followed by the job ID
+const operationPrefix = "operations/"
+
+// operationsListOptions are the fields ListOperations can filter by. The order
+// can't be chosen, as ListOperationsRequest has no order_by field.
+var operationsListOptions = pagination.Options{
+	Sort: []string{"create_time"},
+	Filter: map[string]pagination.FieldType{
+		"done":        pagination.Bool,
+		"job":         pagination.String,
+		"state":       pagination.String,
+		"create_time": pagination.Time,
+	},
+	DefaultSort: "create_time desc",
+}
+
+// OperationsService implements the google.longrunning.Operations service on top
+// of the jobs. The job with ID {id} is the operation "operations/{id}", so the
+// same job can be followed over GRPC and at /v1/jobs/{id}.
+type OperationsService struct {
+	protos.UnimplementedOperationsServer
+}
+
+func (o *OperationsService) GetOperation(ctx context.Context, request *protos.GetOperationRequest) (*protos.Operation, error) {
+	id, err := jobId(request.Name)
+	if err != nil {
+		return nil, err
+	}
+	job, err := jobs.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return NewOperation(job)
+}
+
+func (o *OperationsService) ListOperations(ctx context.Context, request *protos.ListOperationsRequest) (*protos.ListOperationsResponse, error) {
+	if request.Name != "" && request.Name != strings.TrimSuffix(operationPrefix, "/") {
+		return nil, apperrors.ErrInvalidArgument.WithViolations(apperrors.FieldViolation{
+			Field:   "name",
+			Rule:    "oneof",
+			Message: `name must be empty or "operations"`,
+		})
+	}
+	query, err := pagination.FromRPC(operationsListOptions, request.PageSize, request.PageToken, "", request.Filter)
+	if err != nil {
+		return nil, err
+	}
+	list, err := jobs.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page := pagination.Apply(list, query, operationField)
+	response := &protos.ListOperationsResponse{NextPageToken: page.NextPageToken}
+	for _, job := range page.Items {
+		operation, err := NewOperation(job)
+		if err != nil {
+			return nil, err
+		}
+		response.Operations = append(response.Operations, operation)
+	}
+	return response, nil
+}
+
+func (o *OperationsService) DeleteOperation(ctx context.Context, request *protos.DeleteOperationRequest) (*emptypb.Empty, error) {
+	id, err := jobId(request.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := jobs.Delete(ctx, id); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (o *OperationsService) CancelOperation(ctx context.Context, request *protos.CancelOperationRequest) (*emptypb.Empty, error) {
+	id, err := jobId(request.Name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := jobs.Cancel(ctx, id); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (o *OperationsService) WaitOperation(ctx context.Context, request *protos.WaitOperationRequest) (*protos.Operation, error) {
+	id, err := jobId(request.Name)
+	if err != nil {
+		return nil, err
+	}
+	if request.Timeout != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, request.Timeout.AsDuration())
+		defer cancel()
+	}
+	job, err := jobs.Wait(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return NewOperation(job)
+}
+
+// NewOperation describes a job as an operation. The metadata is a JobMetadata.
+// The response of a succeeded job is its result if it is a proto message, and
+// the result converted to a google.protobuf.Value otherwise. Failed and
+// cancelled jobs have an error, rendered like the errors of the RPCs.
+func NewOperation(job jobs.Job) (*protos.Operation, error) {
+	metadata := &protos.JobMetadata{
+		Job:           job.Name,
+		State:         string(job.State),
+		Progress:      int32(job.Progress),
+		CorrelationId: job.CorrelationId,
+		CreateTime:    timestamppb.New(job.CreatedAt),
+	}
+	if job.StartedAt != nil {
+		metadata.StartTime = timestamppb.New(*job.StartedAt)
+	}
+	if job.FinishedAt != nil {
+		metadata.EndTime = timestamppb.New(*job.FinishedAt)
+	}
+	operation := &protos.Operation{Name: operationPrefix + job.Id, Done: job.State.Done()}
+	var err error
+	if operation.Metadata, err = anypb.New(metadata); err != nil {
+		return nil, err
+	}
+
+	switch job.State {
+	case jobs.StateSucceeded:
+		response, err := toAny(job.Result)
+		if err != nil {
+			return nil, apperrors.ErrInternal.Wrap(err)
+		}
+		operation.Result = &protos.Operation_Response{Response: response}
+	case jobs.StateFailed:
+		appErr := apperrors.ErrInternal
+		if job.Error != nil {
+			appErr = apperrors.Lookup(job.Error.Code).WithMessage(job.Error.Message)
+		}
+		operation.Result = &protos.Operation_Error{Error: appErr.Status(job.CorrelationId).Proto()}
+	case jobs.StateCancelled:
+		appErr := apperrors.ErrCanceled.WithMessage("The job was cancelled")
+		operation.Result = &protos.Operation_Error{Error: appErr.Status(job.CorrelationId).Proto()}
+	}
+	return operation, nil
+}
+
+// toAny packs the result of a job
+func toAny(result interface{}) (*anypb.Any, error) {
+	if m, ok := result.(proto.Message); ok {
+		return anypb.New(m)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	value := &structpb.Value{}
+	if err := protojson.Unmarshal(data, value); err != nil {
+		return nil, err
+	}
+	return anypb.New(value)
+}
+
+// operationField returns the value of a field of operationsListOptions
+func operationField(job jobs.Job, field string) interface{} {
+	switch field {
+	case "done":
+		return job.State.Done()
+	case "job":
+		return job.Name
+	case "state":
+		return string(job.State)
+	case "create_time":
+		return job.CreatedAt
+	}
+	return nil
+}
+
+// jobId returns the ID of the job behind the operation named `name`
+func jobId(name string) (string, error) {
+	id := strings.TrimPrefix(name, operationPrefix)
+	if id == name || id == "" {
+		return "", apperrors.ErrInvalidArgument.WithViolations(apperrors.FieldViolation{
+			Field:   "name",
+			Rule:    "pattern",
+			Message: "name must be in the form operations/{id}",
+		})
+	}
+	return id, nil
+}
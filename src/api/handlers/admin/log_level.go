@@ -0,0 +1,66 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"my-microservice/api/response"
+	"my-microservice/api/validation"
+	"my-microservice/logging"
+)
+
+// RegisterValidations registers the custom validation rules of the admin
+// requests. It must be called before serving them.
+func RegisterValidations() error {
+	// Custom validation rule of LogLevelRequest.Level
+	isLevel := func(fl validator.FieldLevel) bool {
+		_, err := logging.ParseLevel(fl.Field().String())
+		return err == nil
+	}
+	return validation.RegisterValidation("loglevel", isLevel, "{field} must be a log level, such as debug, info, warn or error")
+}
+
+// LogLevelRequest is the body of LogLevelPut
+type LogLevelRequest struct {
+	// Level is the log level, such as "debug", "info", "warn" or "error"
+	Level string `json:"level" binding:"required,loglevel" example:"debug"`
+	// TtlSec, if set, restores the previous level after this many seconds
+	TtlSec int32 `json:"ttl_sec,omitempty" binding:"gte=0" example:"600"`
+}
+
+// LogLevelsGet returns the global log level and the per logger overrides
+func LogLevelsGet(c *gin.Context) {
+	response.SuccessResponse(c, logging.GetLevels())
+}
+
+// LogLevelPut sets the global log level, or the level of the logger named by
+// the `logger` path parameter, which matches the "package" log field
+func LogLevelPut(c *gin.Context) {
+	var request LogLevelRequest
+	if err := validation.Bind(c, &request); err != nil {
+		response.ErrorResponse(c, nil, err)
+		return
+	}
+	level, _ := logging.ParseLevel(request.Level)
+
+	logging.SetLevel(c.Param("logger"), level, time.Duration(request.TtlSec)*time.Second, callerFromRequest(c))
+	response.SuccessResponse(c, logging.GetLevels())
+}
+
+// LogLevelDelete resets the global log level to its default, or makes the
+// logger named by the `logger` path parameter follow the global level again
+func LogLevelDelete(c *gin.Context) {
+	logging.ResetLevel(c.Param("logger"), callerFromRequest(c))
+	response.SuccessResponse(c, logging.GetLevels())
+}
+
+func callerFromRequest(c *gin.Context) logging.Caller {
+	return logging.Caller{
+		Protocol:      "http",
+		Address:       c.ClientIP(),
+		UserAgent:     c.Request.UserAgent(),
+		CorrelationId: c.MustGet("correlation_id").(string),
+	}
+}
@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"my-microservice/api/response"
+	"my-microservice/api/validation"
+	"my-microservice/health"
+	"my-microservice/logging"
+)
+
+// MaintenanceState is the body of MaintenancePut and the data returned by the
+// maintenance endpoints
+type MaintenanceState struct {
+	Enabled *bool `json:"enabled" binding:"required" example:"true"`
+}
+
+// MaintenanceGet reports whether the maintenance mode is active
+func MaintenanceGet(c *gin.Context) {
+	enabled := health.Maintenance()
+	response.SuccessResponse(c, MaintenanceState{Enabled: &enabled})
+}
+
+// MaintenancePut turns the maintenance mode on or off
+func MaintenancePut(c *gin.Context) {
+	var request MaintenanceState
+	if err := validation.Bind(c, &request); err != nil {
+		response.ErrorResponse(c, nil, err)
+		return
+	}
+
+	previous := health.Maintenance()
+	health.SetMaintenance(*request.Enabled)
+	logging.Audit("Maintenance mode changed", callerFromRequest(c), "previous_enabled", previous, "enabled", *request.Enabled)
+	MaintenanceGet(c)
+}
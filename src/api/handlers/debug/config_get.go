@@ -0,0 +1,13 @@
+package debug
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"my-microservice/api/response"
+	"my-microservice/configuration"
+)
+
+// ConfigGet returns the effective configuration. Secrets are excluded.
+func ConfigGet(c *gin.Context) {
+	response.SuccessResponse(c, configuration.AppConfig())
+}
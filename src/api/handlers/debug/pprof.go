@@ -0,0 +1,24 @@
+package debug
+
+import (
+	"net/http/pprof"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pprof serves the runtime profiling data of net/http/pprof. It must be mounted
+// on /debug/pprof/*name, the path net/http/pprof expects.
+func Pprof(c *gin.Context) {
+	switch c.Param("name") {
+	case "/cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "/profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "/symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "/trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Index(c.Writer, c.Request)
+	}
+}
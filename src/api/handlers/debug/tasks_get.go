@@ -0,0 +1,14 @@
+package debug
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"my-microservice/api/response"
+	"my-microservice/tasks"
+)
+
+// TasksGet lists the background tasks and in-flight requests which are
+// currently running, oldest first
+func TasksGet(c *gin.Context) {
+	response.SuccessResponse(c, tasks.List())
+}
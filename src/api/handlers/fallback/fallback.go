@@ -0,0 +1,49 @@
+package fallback
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"my-microservice/api/response"
+)
+
+// NoRoute answers requests to unknown routes with the JSONNotFoundResult envelope
+func NoRoute(c *gin.Context) {
+	response.NotFoundResponse(c, nil)
+}
+
+// NoMethod answers requests to known routes with an unsupported method with
+// 405. The methods the route supports are looked up in `routes`.
+func NoMethod(routes func() gin.RoutesInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var allowed []string
+		seen := make(map[string]bool)
+		for _, route := range routes() {
+			if !seen[route.Method] && routeMatches(route.Path, c.Request.URL.Path) {
+				seen[route.Method] = true
+				allowed = append(allowed, route.Method)
+			}
+		}
+		response.MethodNotAllowedResponse(c, allowed)
+	}
+}
+
+// routeMatches reports whether `path` matches the route template `route`, which
+// may contain :param and *catchAll segments
+func routeMatches(route string, path string) bool {
+	routeSegments := strings.Split(route, "/")
+	pathSegments := strings.Split(path, "/")
+	for i, segment := range routeSegments {
+		if strings.HasPrefix(segment, "*") {
+			return true
+		}
+		if i >= len(pathSegments) {
+			return false
+		}
+		if !strings.HasPrefix(segment, ":") && segment != pathSegments[i] {
+			return false
+		}
+	}
+	return len(routeSegments) == len(pathSegments)
+}
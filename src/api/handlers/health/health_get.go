@@ -0,0 +1,31 @@
+package health
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/coderollers/go-utils"
+	"github.com/gin-gonic/gin"
+
+	"my-microservice/api/response"
+	"my-microservice/health"
+)
+
+// LivenessGet reports that the process is alive and able to serve requests
+func LivenessGet(c *gin.Context) {
+	response.SuccessResponse[any](c, nil)
+}
+
+// ReadinessGet reports whether the microservice is ready to receive traffic. It
+// turns not-ready while draining on shutdown.
+func ReadinessGet(c *gin.Context) {
+	if !health.Ready() {
+		response.FailureResponse(c, nil, utils.HttpError{
+			Code:    http.StatusServiceUnavailable,
+			Err:     errors.New("not ready"),
+			Message: "The service is not ready to receive traffic",
+		})
+		return
+	}
+	response.SuccessResponse[any](c, nil)
+}
@@ -0,0 +1,54 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	_ "my-microservice/api/models" // Referenced by the Swagger annotations
+	"my-microservice/api/response"
+	"my-microservice/api/validation"
+	"my-microservice/jobs"
+)
+
+// GreetingAsyncPost godoc
+// @Summary Sample asynchronous POST handler
+// @Description Sample handler submitting its work as a job. It answers at once with 202 Accepted and a Location
+// @Description header pointing to the job, which holds the GreetingResponse once it has succeeded.
+// @ID greeting-async-post
+// @Accept json
+// @Produce json,application/problem+json
+// @Param request body GreetingRequest true "The name to greet"
+// @Success 202 {object} models.JSONAcceptedResult[jobs.Job] "The job was queued"
+// @Header 202 {string} Location "The URL of the job"
+// @Failure 400 {object} models.JSONFailureResult{data=models.ValidationErrors} "The request data could not be processed"
+// @Failure 429 {object} models.JSONFailureResult "The job queue is full"
+// @Failure default {object} models.ProblemDetails "Any failure, when rendered as application/problem+json"
+// @Router /v1/greetings/async [post]
+func GreetingAsyncPost(c *gin.Context) {
+	var request GreetingRequest
+	if err := validation.Bind(c, &request); err != nil {
+		response.ErrorResponse(c, nil, err)
+		return // Always return after responding to client!
+	}
+
+	// The job outlives the request, so it must not use anything bound to it
+	job, err := jobs.Submit(c, "GreetingAsync", func(ctx context.Context, progress func(percent int)) (interface{}, error) {
+		for step := 1; step <= 5; step++ {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Second):
+				progress(step * 20)
+			}
+		}
+		return GreetingResponse{Message: fmt.Sprintf("Hello there, %s", request.Name)}, nil
+	})
+	if err != nil {
+		response.ErrorResponse(c, nil, err)
+		return // Always return after responding to client!
+	}
+	response.AcceptedResponse(c, job.Id, jobLocation(job.Id), job)
+}
@@ -0,0 +1,43 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coderollers/go-logger"
+
+	_ "my-microservice/api/models" // Referenced by the Swagger annotations
+)
+
+// GreetingRequest is the body of GreetingPost
+type GreetingRequest struct {
+	// Name is the name to greet
+	Name string `json:"name" binding:"required,max=64" example:"John"`
+}
+
+// GreetingResponse is the data returned by GreetingPost
+type GreetingResponse struct {
+	Message string `json:"message" example:"Hello there, John"`
+}
+
+// GreetingPost godoc
+// @Summary Sample typed POST handler
+// @Description Sample handler written as a typed function and adapted to Gin with endpoint.Handle, which takes
+// @Description care of binding, validation, tracing and rendering the response.
+// @ID greeting-post
+// @Accept json
+// @Produce json,application/problem+json
+// @Param request body GreetingRequest true "The name to greet"
+// @Success 200 {object} models.JSONSuccessResult[v1.GreetingResponse] "Positive response"
+// @Failure 400 {object} models.JSONFailureResult{data=models.ValidationErrors} "The request data could not be processed"
+// @Failure 500 {object} models.JSONFailureResult "An internal error has occurred, most likely due to an uncaught exception"
+// @Failure default {object} models.ProblemDetails "Any failure, when rendered as application/problem+json"
+// @Router /v1/greetings [post]
+func GreetingPost(ctx context.Context, req GreetingRequest) (GreetingResponse, error) {
+	log := logger.SugaredLogger().WithContextCorrelationId(ctx).With("package", "handlers", "action", "GreetingPost")
+	log.Debugf("Greeting %s", req.Name)
+
+	// Errors from the catalog are rendered with their status code, for example:
+	// return GreetingResponse{}, apperrors.ErrNotFound.WithMetadata("name", req.Name)
+	return GreetingResponse{Message: fmt.Sprintf("Hello there, %s", req.Name)}, nil
+}
@@ -2,37 +2,66 @@ package v1
 
 import (
 	"github.com/coderollers/go-logger"
-	"github.com/coderollers/go-stats/concurrency"
 	"github.com/coderollers/go-utils"
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
+	_ "my-microservice/api/models" // Referenced by the Swagger annotations
 	"my-microservice/api/response"
+	"my-microservice/api/validation"
 	"my-microservice/tracer"
 )
 
+// IndexRequest is the input of IndexGet. Fields are bound from the path
+// (`uri` tags), the query string (`form` tags), the headers (`header` tags) and
+// the JSON body (`json` tags), then checked against their `binding` rules.
+type IndexRequest struct {
+	// Name is the name to greet
+	Name string `form:"name" binding:"omitempty,alphanum,max=32" example:"John"`
+	// Language is the preferred language of the greeting
+	Language string `header:"Accept-Language" binding:"omitempty,max=35" example:"en"`
+}
+
+// IndexResponse is the data returned by IndexGet
+type IndexResponse struct {
+	Motto    string `json:"Motto" example:"Hello world!"`
+	Greeting string `json:"Greeting,omitempty" example:"Hello, John"`
+}
+
 // IndexGet godoc
 // @Summary Sample GET handler
-// @Description Sample GET handler
+// @Description Sample GET handler. Failures are rendered as problem details instead of the JSONFailureResult
+// @Description envelope if the client accepts application/problem+json, or if ERROR_FORMAT is "problem".
+// @Description Successful responses are rendered in the media type negotiated with the Accept header, and
+// @Description indented if the `pretty` query parameter is set.
 // @ID index-get
 // @Accept json
-// @Produce json
-// @Success 200 {object} models.JSONSuccessResult "Positive response"
-// @Failure 400 {object} models.JSONFailureResult "The request data could not be processed"
+// @Produce json,application/problem+json,application/yaml,application/msgpack
+// @Param name query string false "The name to greet" maxlength(32)
+// @Param pretty query bool false "Indent the JSON response"
+// @Success 200 {object} models.JSONSuccessResult[v1.IndexResponse] "Positive response"
+// @Failure 400 {object} models.JSONFailureResult{data=models.ValidationErrors} "The request data could not be processed"
 // @Failure 404 {object} models.JSONNotFoundResult "The object was not found"
+// @Failure 406 {object} models.JSONFailureResult "None of the accepted media types can be produced"
 // @Failure 500 {object} models.JSONFailureResult "An internal error has occurred, most likely due to an uncaught exception"
 // @Failure 503 {object} models.JSONFailureResult "An error has occurred, most likely due to an unavailable dependency"
+// @Failure default {object} models.ProblemDetails "Any failure, when rendered as application/problem+json"
 // @Router /v1/ [get]
 func IndexGet(c *gin.Context) {
-	concurrency.GlobalWaitGroup.Add(1)
-	defer concurrency.GlobalWaitGroup.Done()
 	var (
 		log           = logger.SugaredLogger().WithContextCorrelationId(c).With("package", "handlers", "action", "GetTask")
 		correlationId = c.MustGet("correlation_id").(string)
 		r             interface{}
+		request       IndexRequest
 	)
 
+	// Bind and validate the input. Field violations are returned in data.errors.
+	if err := validation.Bind(c, &request); err != nil {
+		response.ErrorResponse(c, nil, err)
+		return // Always return after responding to client!
+	}
+
 	// Create tracer span
 	_, span := tracer.Tracer.Start(c.Request.Context(), "IndexGet")
 	defer span.End()
@@ -44,7 +73,10 @@ func IndexGet(c *gin.Context) {
 	log.Debugf("Correlation ID for request: %s", correlationId)
 
 	// Do some work and get the response data you want to send back to the client
-	responseData := map[string]string{"Motto": "Hello world!"}
+	responseData := &IndexResponse{Motto: "Hello world!"}
+	if request.Name != "" {
+		responseData.Greeting = "Hello, " + request.Name
+	}
 
 	// Example not found response
 	if responseData == nil {
@@ -64,6 +96,9 @@ func IndexGet(c *gin.Context) {
 		return // Always return after responding to client!
 	}
 
+	// Example error response using the error catalog, rendered with the status of the catalog entry
+	// response.ErrorResponse(c, nil, apperrors.ErrNotFound.WithMetadata("resource", "motto"))
+
 	// Example positive response
 	response.SuccessResponse(c, responseData)
 }
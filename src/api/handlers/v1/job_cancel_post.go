@@ -0,0 +1,25 @@
+package v1
+
+import (
+	"context"
+
+	_ "my-microservice/api/models" // Referenced by the Swagger annotations
+	"my-microservice/jobs"
+)
+
+// JobCancelPost godoc
+// @Summary Cancel a job
+// @Description Cancels an asynchronous job. A queued job is cancelled at once, a running one as soon as it
+// @Description stops, so the returned job may still be running.
+// @ID job-cancel-post
+// @Produce json,application/problem+json
+// @Param id path string true "The ID of the job" format(uuid)
+// @Success 200 {object} models.JSONSuccessResult[jobs.Job] "The job"
+// @Failure 400 {object} models.JSONFailureResult{data=models.ValidationErrors} "The request data could not be processed"
+// @Failure 404 {object} models.JSONFailureResult "The job was not found"
+// @Failure 409 {object} models.JSONFailureResult "The job is already done"
+// @Failure default {object} models.ProblemDetails "Any failure, when rendered as application/problem+json"
+// @Router /v1/jobs/{id}/cancel [post]
+func JobCancelPost(ctx context.Context, req JobRequest) (jobs.Job, error) {
+	return jobs.Cancel(ctx, req.Id)
+}
@@ -0,0 +1,36 @@
+package v1
+
+import (
+	"context"
+
+	_ "my-microservice/api/models" // Referenced by the Swagger annotations
+	"my-microservice/configuration"
+	"my-microservice/jobs"
+)
+
+// JobRequest identifies a job by the `id` path parameter
+type JobRequest struct {
+	Id string `uri:"id" binding:"required,uuid" example:"5b0e6a0c-4a8e-4c1e-9f5e-2b8f0a8d7c11"`
+}
+
+// JobGet godoc
+// @Summary Get a job
+// @Description Returns the state, progress and result of an asynchronous job. The URL is given by the Location
+// @Description header of the requests answered with 202 Accepted. Finished jobs are kept for JOBS_RETENTION_SEC.
+// @ID job-get
+// @Produce json,application/problem+json
+// @Param id path string true "The ID of the job" format(uuid)
+// @Success 200 {object} models.JSONSuccessResult[jobs.Job] "The job"
+// @Failure 400 {object} models.JSONFailureResult{data=models.ValidationErrors} "The request data could not be processed"
+// @Failure 404 {object} models.JSONFailureResult "The job was not found"
+// @Failure default {object} models.ProblemDetails "Any failure, when rendered as application/problem+json"
+// @Router /v1/jobs/{id} [get]
+func JobGet(ctx context.Context, req JobRequest) (jobs.Job, error) {
+	return jobs.Get(ctx, req.Id)
+}
+
+// jobLocation returns the URL of JobGet for the job with the given ID, to be
+// sent in the Location header of the requests answered with 202 Accepted
+func jobLocation(id string) string {
+	return configuration.AppConfig().IngressPrefix + "/v1/jobs/" + id
+}
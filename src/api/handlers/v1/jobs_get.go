@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+
+	_ "my-microservice/api/models" // Referenced by the Swagger annotations
+	"my-microservice/api/pagination"
+	"my-microservice/api/response"
+	"my-microservice/jobs"
+)
+
+// jobsListOptions are the fields JobsGet can sort and filter by
+var jobsListOptions = pagination.Options{
+	Sort: []string{"name", "state", "progress", "created_at", "started_at", "finished_at"},
+	Filter: map[string]pagination.FieldType{
+		"name":        pagination.String,
+		"state":       pagination.String,
+		"progress":    pagination.Number,
+		"created_at":  pagination.Time,
+		"started_at":  pagination.Time,
+		"finished_at": pagination.Time,
+	},
+	DefaultSort: "created_at desc",
+}
+
+// JobsGet godoc
+// @Summary List the jobs
+// @Description Lists the asynchronous jobs, newest first unless sorted otherwise. The next page is requested by
+// @Description passing next_page_token as page_token, with the same sort and filter.
+// @ID jobs-get
+// @Produce json,application/problem+json
+// @Param page_size query int false "The maximum number of jobs to return" minimum(0)
+// @Param page_token query string false "The next_page_token of the previous page"
+// @Param sort query string false "Comma separated fields to sort by, descending when prefixed with -" example(-created_at,name)
+// @Param filter query string false "Conditions joined with AND, such as: state = running AND progress >= 50"
+// @Success 200 {object} models.JSONPageResult[jobs.Job] "A page of jobs"
+// @Failure 400 {object} models.JSONFailureResult{data=models.ValidationErrors} "The request data could not be processed"
+// @Failure default {object} models.ProblemDetails "Any failure, when rendered as application/problem+json"
+// @Router /v1/jobs [get]
+func JobsGet(c *gin.Context) {
+	query, err := pagination.FromQuery(c, jobsListOptions)
+	if err != nil {
+		response.ErrorResponse(c, nil, err)
+		return // Always return after responding to client!
+	}
+
+	list, err := jobs.List(c)
+	if err != nil {
+		response.ErrorResponse(c, nil, err)
+		return // Always return after responding to client!
+	}
+	response.PageResponse(c, pagination.Apply(list, query, jobField))
+}
+
+// jobField returns the value of a field of jobsListOptions
+func jobField(job jobs.Job, field string) interface{} {
+	switch field {
+	case "name":
+		return job.Name
+	case "state":
+		return string(job.State)
+	case "progress":
+		return job.Progress
+	case "created_at":
+		return job.CreatedAt
+	case "started_at":
+		return job.StartedAt
+	case "finished_at":
+		return job.FinishedAt
+	}
+	return nil
+}
@@ -7,116 +7,146 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/coderollers/go-logger"
-	"github.com/coderollers/go-stats/concurrency"
 	"github.com/gin-gonic/gin"
 	"github.com/improbable-eng/grpc-web/go/grpcweb"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
+	"golang.org/x/net/netutil"
 	"google.golang.org/grpc"
 
 	"my-microservice/configuration"
+	"my-microservice/lifecycle"
 )
 
-func StartHttpServer(ctx context.Context, ginRouter *gin.Engine, grpcServer *grpc.Server, grpcWebWrapper *grpcweb.WrappedGrpcServer) {
-	concurrency.GlobalWaitGroup.Add(1)
-	defer concurrency.GlobalWaitGroup.Done()
-
+// HttpServerComponent serves the Gin router on the HTTP port. If grpcWebWrapper
+// is set, native GRPC and GRPC-Web are multiplexed on the same port, and the GRPC
+// server is drained and stopped together with the HTTP server.
+func HttpServerComponent(ginRouter *gin.Engine, grpcServer *grpc.Server, grpcWebWrapper *grpcweb.WrappedGrpcServer) lifecycle.Component {
 	conf := configuration.AppConfig()
 	log := logger.SugaredLogger()
 
-	if grpcWebWrapper == nil {
-		// No GRPC-Web, Gin-only server
-		httpSrv := &http.Server{
-			Addr:    fmt.Sprintf(":%d", conf.HttpPort),
-			Handler: ginRouter,
-		}
+	var (
+		httpSrv     *http.Server
+		grpcStreams *streamTracker
+	)
+
+	return lifecycle.Component{
+		Name:      "http-server",
+		DependsOn: []string{"telemetry"},
+		Start: func(ctx context.Context) error {
+			http2Srv := newHardenedHttp2Server()
+			if grpcWebWrapper == nil {
+				// No GRPC-Web, Gin-only server
+				httpSrv = newHardenedServer(ginRouter)
+			} else {
+				// GRPC native + GRPC-Web + Gin on same port
+				grpcStreams = &streamTracker{}
+				httpSrv = newHardenedServer(h2c.NewHandler(newMultiplexedHandler(ginRouter, grpcStreams.track(grpcServer), grpcWebWrapper), http2Srv))
+			}
+			// Lets Shutdown send GOAWAY on HTTP/2 connections, which h2c otherwise hides from it
+			if err := http2.ConfigureServer(httpSrv, http2Srv); err != nil {
+				return fmt.Errorf("cannot configure HTTP/2: %w", err)
+			}
+
+			listener, err := newLimitedListener(conf.HttpPort)
+			if err != nil {
+				return fmt.Errorf("cannot open listener socket: %w", err)
+			}
 
-		// Start the HTTP Server
-		go func() {
-			log.Infof("Listening on port %d", conf.HttpPort)
-			if err := httpSrv.ListenAndServe(); err != nil {
-				if err != http.ErrServerClosed {
+			// Start the HTTP Server
+			go func() {
+				log.Infof("Listening on port %d", conf.HttpPort)
+				if err := httpSrv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
 					log.Fatalf("Unrecoverable HTTP Server failure: %s", err.Error())
 				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			log.Infof("Attempting to shutdown the HTTP server")
+			err := httpSrv.Shutdown(ctx)
+			if err == nil {
+				log.Infof("HTTP Server was shutdown successfully")
+			}
+			if grpcStreams == nil {
+				return err
 			}
-		}()
-
-		// Block until SIGTERM/SIGINT
-		<-ctx.Done()
-
-		// Clean up and shutdown the HTTP server
-		cleanCtx, cancel := context.WithTimeout(context.Background(), time.Duration(conf.CleanupTimeoutSec)*time.Second)
-		defer cancel()
-		log.Infof("Attempting to shutdown the HTTP server with a timeout of %d seconds", conf.CleanupTimeoutSec)
-		if err := httpSrv.Shutdown(cleanCtx); err != nil {
-			log.Errorf("HTTP server failed to shutdown gracefully: %s", err.Error())
-		} else {
-			log.Infof("HTTP Server was shutdown successfully")
-		}
-	} else {
-		// GRPC native + GRPC-Web + Gin on same port
 
-		var mixedHandler, http1Handler http.Handler
+			// Native GRPC connections are hijacked from the HTTP server, so their streams
+			// must be drained separately within the same deadline
+			if drainErr := grpcStreams.wait(ctx); drainErr != nil {
+				err = errors.Join(err, fmt.Errorf("in-flight GRPC streams were cancelled: %w", drainErr))
+			} else {
+				log.Infof("GRPC Server was shutdown successfully")
+			}
+			grpcServer.Stop()
+			return err
+		},
+	}
+}
 
-		if conf.Development {
-			http1Handler = http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-				if grpcWebWrapper.IsGrpcWebRequest(request) {
-					log.Debugf("Request handled by GRPC-Web")
-					grpcWebWrapper.ServeHTTP(writer, request)
-					return
-				}
-				log.Debugf("Request handled by Gin")
-				ginRouter.ServeHTTP(writer, request)
-			})
-			mixedHandler = newHttpAndGrpcMuxWithDebug(http1Handler, grpcServer)
-		} else {
-			// Handle GRPC-Web and Gin multiplexing
-			http1Handler = http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-				if grpcWebWrapper.IsGrpcWebRequest(request) {
-					grpcWebWrapper.ServeHTTP(writer, request)
-					return
-				}
-				ginRouter.ServeHTTP(writer, request)
-			})
+// newMultiplexedHandler routes native GRPC requests to grpcHandler, GRPC-Web
+// requests to grpcWebWrapper and everything else to Gin
+func newMultiplexedHandler(ginRouter *gin.Engine, grpcHandler http.Handler, grpcWebWrapper *grpcweb.WrappedGrpcServer) http.Handler {
+	conf := configuration.AppConfig()
+	log := logger.SugaredLogger()
 
-			// Add GRPC Native to the multiplexer
-			mixedHandler = newHttpAndGrpcMux(http1Handler, grpcServer)
-		}
-		http2Srv := &http2.Server{}
-		http1Srv := &http.Server{
-			Handler: h2c.NewHandler(mixedHandler, http2Srv),
-		}
+	if conf.Development {
+		http1Handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if grpcWebWrapper.IsGrpcWebRequest(request) {
+				log.Debugf("Request handled by GRPC-Web")
+				grpcWebWrapper.ServeHTTP(writer, request)
+				return
+			}
+			log.Debugf("Request handled by Gin")
+			ginRouter.ServeHTTP(writer, request)
+		})
+		return newHttpAndGrpcMuxWithDebug(http1Handler, grpcHandler)
+	}
 
-		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", conf.HttpPort))
-		if err != nil {
-			log.Fatalf("Cannot open listener socket: %s", err.Error())
+	// Handle GRPC-Web and Gin multiplexing
+	http1Handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if grpcWebWrapper.IsGrpcWebRequest(request) {
+			grpcWebWrapper.ServeHTTP(writer, request)
+			return
 		}
+		ginRouter.ServeHTTP(writer, request)
+	})
 
-		// Start the HTTP Server
-		go func() {
-			concurrency.GlobalWaitGroup.Add(1)
-			defer concurrency.GlobalWaitGroup.Done()
+	// Add GRPC Native to the multiplexer
+	return newHttpAndGrpcMux(http1Handler, grpcHandler)
+}
 
-			if err = http1Srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
-				log.Fatalf("Failed to serve multiplexed endpoint: %s", err.Error())
-			}
-		}()
-
-		// Block until SIGTERM/SIGINT
-		<-ctx.Done()
-
-		// Clean up and shutdown the HTTP server
-		cleanCtx, cancel := context.WithTimeout(context.Background(), time.Duration(conf.CleanupTimeoutSec)*time.Second)
-		defer cancel()
-		log.Infof("Attempting to shutdown the HTTP server with a timeout of %d seconds", conf.CleanupTimeoutSec)
-		if err := http1Srv.Shutdown(cleanCtx); err != nil {
-			log.Errorf("HTTP server failed to shutdown gracefully: %s", err.Error())
-		} else {
-			log.Infof("HTTP Server was shutdown successfully")
-		}
+// streamTracker keeps count of the native GRPC streams served through the
+// multiplexed HTTP listener, so that they can be drained on shutdown
+type streamTracker struct {
+	wg sync.WaitGroup
+}
+
+func (t *streamTracker) track(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		t.wg.Add(1)
+		defer t.wg.Done()
+		handler.ServeHTTP(writer, request)
+	})
+}
+
+// wait blocks until all tracked streams are done or ctx expires
+func (t *streamTracker) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
@@ -142,3 +172,47 @@ func newHttpAndGrpcMux(httpHandler http.Handler, grpcHandler http.Handler) http.
 		httpHandler.ServeHTTP(writer, request)
 	})
 }
+
+// newHardenedServer creates an HTTP server using the configured timeouts and
+// header size limit
+func newHardenedServer(handler http.Handler) *http.Server {
+	conf := configuration.AppConfig().HttpServer
+
+	return &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: time.Duration(conf.ReadHeaderTimeoutSec) * time.Second,
+		ReadTimeout:       time.Duration(conf.ReadTimeoutSec) * time.Second,
+		WriteTimeout:      time.Duration(conf.WriteTimeoutSec) * time.Second,
+		IdleTimeout:       time.Duration(conf.IdleTimeoutSec) * time.Second,
+		MaxHeaderBytes:    int(conf.MaxHeaderBytes),
+	}
+}
+
+// newHardenedHttp2Server creates an HTTP/2 server using the configured stream
+// and frame limits
+func newHardenedHttp2Server() *http2.Server {
+	conf := configuration.AppConfig().HttpServer
+
+	return &http2.Server{
+		MaxConcurrentStreams:         uint32(conf.Http2MaxConcurrentStreams),
+		MaxReadFrameSize:             uint32(conf.Http2MaxReadFrameSize),
+		MaxUploadBufferPerConnection: conf.Http2MaxUploadBufferPerConnection,
+		MaxUploadBufferPerStream:     conf.Http2MaxUploadBufferPerStream,
+		IdleTimeout:                  time.Duration(conf.IdleTimeoutSec) * time.Second,
+	}
+}
+
+// newLimitedListener opens a TCP listener on `port` which accepts at most the
+// configured number of simultaneous connections
+func newLimitedListener(port int32) (net.Listener, error) {
+	conf := configuration.AppConfig().HttpServer
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return nil, err
+	}
+	if conf.MaxConnections > 0 {
+		listener = netutil.LimitListener(listener, int(conf.MaxConnections))
+	}
+	return listener, nil
+}
@@ -0,0 +1,247 @@
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/coderollers/go-logger"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"my-microservice/configuration"
+)
+
+// defaultGrpcLevels are the access log levels of the status codes, unless
+// overridden by configuration. Codes pointing at server side problems are
+// errors, codes which may need attention are warnings.
+var defaultGrpcLevels = map[codes.Code]string{
+	codes.OK:                 "info",
+	codes.Canceled:           "info",
+	codes.Unknown:            "error",
+	codes.InvalidArgument:    "info",
+	codes.DeadlineExceeded:   "warn",
+	codes.NotFound:           "info",
+	codes.AlreadyExists:      "info",
+	codes.PermissionDenied:   "warn",
+	codes.ResourceExhausted:  "warn",
+	codes.FailedPrecondition: "warn",
+	codes.Aborted:            "warn",
+	codes.OutOfRange:         "warn",
+	codes.Unimplemented:      "error",
+	codes.Internal:           "error",
+	codes.Unavailable:        "warn",
+	codes.DataLoss:           "error",
+	codes.Unauthenticated:    "info",
+}
+
+// AccessLogUnary writes a structured access log entry for every unary call,
+// through the logger named "accesslog". In Development mode, the request and
+// response messages can be logged as well, with sensitive fields redacted.
+func AccessLogUnary() grpc.UnaryServerInterceptor {
+	conf := configuration.AppConfig()
+	levels := grpcLevels()
+	logPayloads := conf.Development && conf.AccessLog.GrpcLogPayloads
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if skipMethod(info.FullMethod, conf.AccessLog.GrpcSkipMethods) {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		// Panics are recovered further up the chain, log them as the Internal they become
+		defer func() {
+			if p := recover(); p != nil {
+				writeAccessLog(ctx, errPanic, levels[codes.Internal], callFields(ctx, info.FullMethod, errPanic, time.Since(start)))
+				panic(p)
+			}
+		}()
+		resp, err := handler(ctx, req)
+
+		fields := callFields(ctx, info.FullMethod, err, time.Since(start))
+		if logPayloads {
+			fields = append(fields, "request", redactedPayload(req, conf.AccessLog.RedactFields))
+			if err == nil {
+				fields = append(fields, "response", redactedPayload(resp, conf.AccessLog.RedactFields))
+			}
+		}
+		writeAccessLog(ctx, err, levels[status.Code(err)], fields)
+		return resp, err
+	}
+}
+
+// AccessLogStream is the streaming counterpart of AccessLogUnary. It logs the
+// number of messages received and sent once the stream is done, but never
+// their payloads.
+func AccessLogStream() grpc.StreamServerInterceptor {
+	conf := configuration.AppConfig()
+	levels := grpcLevels()
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if skipMethod(info.FullMethod, conf.AccessLog.GrpcSkipMethods) {
+			return handler(srv, ss)
+		}
+
+		start := time.Now()
+		stream := &countingStream{ServerStream: ss}
+		defer func() {
+			if p := recover(); p != nil {
+				fields := callFields(ss.Context(), info.FullMethod, errPanic, time.Since(start))
+				fields = append(fields, "messages_received", stream.received, "messages_sent", stream.sent)
+				writeAccessLog(ss.Context(), errPanic, levels[codes.Internal], fields)
+				panic(p)
+			}
+		}()
+		err := handler(srv, stream)
+
+		fields := callFields(ss.Context(), info.FullMethod, err, time.Since(start))
+		fields = append(fields, "messages_received", stream.received, "messages_sent", stream.sent)
+		writeAccessLog(ss.Context(), err, levels[status.Code(err)], fields)
+		return err
+	}
+}
+
+// grpcLevels merges the configured levels into the default ones
+func grpcLevels() map[codes.Code]string {
+	conf := configuration.AppConfig()
+
+	levels := make(map[codes.Code]string, len(defaultGrpcLevels))
+	for code, level := range defaultGrpcLevels {
+		levels[code] = level
+		if override, ok := conf.AccessLog.GrpcLevels[code.String()]; ok {
+			levels[code] = override
+		}
+	}
+	return levels
+}
+
+func skipMethod(fullMethod string, skipMethods []string) bool {
+	for _, prefix := range skipMethods {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func callFields(ctx context.Context, fullMethod string, err error, duration time.Duration) []interface{} {
+	fields := []interface{}{
+		"method", fullMethod,
+		"code", status.Code(err).String(),
+		"duration_ms", float64(duration) / float64(time.Millisecond),
+	}
+	if err != nil {
+		fields = append(fields, "error", status.Convert(err).Message())
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		fields = append(fields, "peer_address", p.Addr.String())
+	}
+	if traceId := traceIdFromContext(ctx); traceId != "" {
+		fields = append(fields, "trace_id", traceId)
+	}
+	return fields
+}
+
+func writeAccessLog(ctx context.Context, err error, level string, fields []interface{}) {
+	log := logger.SugaredLogger().WithContextCorrelationId(ctx).With("package", "accesslog")
+	msg := "Call served"
+	if err != nil {
+		msg = "Call failed"
+	}
+	switch level {
+	case "debug":
+		log.Debugw(msg, fields...)
+	case "warn":
+		log.Warnw(msg, fields...)
+	case "error":
+		log.Errorw(msg, fields...)
+	default:
+		log.Infow(msg, fields...)
+	}
+}
+
+// traceIdFromContext returns the ID of the trace the call belongs to, taken from
+// the span started by the tracing interceptors
+func traceIdFromContext(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// redactedPayload converts a message to a JSON compatible value, replacing the
+// values of the fields named in `redactFields`. Field names match whether they
+// are written in snake_case, as in the proto files, or in lowerCamelCase, as in
+// the JSON of the payload.
+func redactedPayload(message interface{}, redactFields []string) interface{} {
+	m, ok := message.(proto.Message)
+	if !ok {
+		return nil
+	}
+	raw, err := protojson.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	var payload interface{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil
+	}
+	redacted := make(map[string]bool, len(redactFields))
+	for _, name := range redactFields {
+		redacted[normalizeFieldName(name)] = true
+	}
+	return redact(payload, redacted)
+}
+
+func redact(value interface{}, redacted map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if redacted[normalizeFieldName(key)] {
+				v[key] = "[REDACTED]"
+				continue
+			}
+			v[key] = redact(field, redacted)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redact(item, redacted)
+		}
+	}
+	return value
+}
+
+// normalizeFieldName makes "api_key", "apiKey" and "ApiKey" equal
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// countingStream counts the messages received and sent on a stream
+type countingStream struct {
+	grpc.ServerStream
+	received int
+	sent     int
+}
+
+func (s *countingStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received++
+	}
+	return err
+}
+
+func (s *countingStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent++
+	}
+	return err
+}
@@ -0,0 +1,34 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// BearerTokenUnary rejects unary calls with Unauthenticated unless they carry
+// `token` in the authorization metadata. If `token` is empty, all calls are let
+// through.
+func BearerTokenUnary(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if token == "" {
+			return handler(ctx, req)
+		}
+
+		var given string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("authorization"); len(values) > 0 {
+				given, _ = strings.CutPrefix(values[0], "Bearer ")
+			}
+		}
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,44 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"my-microservice/configuration"
+)
+
+// correlationIdMetadataKey is the GRPC counterpart of the X-Correlation-ID header
+const correlationIdMetadataKey = "x-correlation-id"
+
+// CorrelationIdUnary stores the correlation ID sent by the client, or a new one,
+// in the context under configuration.CorrelationIdKey and returns it to the
+// client in the response headers.
+func CorrelationIdUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withCorrelationId(ctx), req)
+	}
+}
+
+// CorrelationIdStream is the streaming counterpart of CorrelationIdUnary
+func CorrelationIdStream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: withCorrelationId(ss.Context())})
+	}
+}
+
+func withCorrelationId(ctx context.Context) context.Context {
+	var correlationId string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(correlationIdMetadataKey); len(values) > 0 {
+			correlationId = values[0]
+		}
+	}
+	if correlationId == "" {
+		correlationId = uuid.New().String()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(correlationIdMetadataKey, correlationId))
+	return context.WithValue(ctx, configuration.CorrelationIdKey, correlationId)
+}
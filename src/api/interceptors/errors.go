@@ -0,0 +1,51 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+
+	"github.com/coderollers/go-logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"my-microservice/apperrors"
+	"my-microservice/configuration"
+)
+
+// ErrorsUnary renders the errors returned by the handlers as GRPC statuses.
+// apperrors.Error values get their code, ErrorInfo and a RequestInfo carrying
+// the correlation ID. Status errors are passed through, any other error is
+// logged and replaced by an Internal status which does not leak its message.
+func ErrorsUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, renderError(ctx, info.FullMethod, err)
+	}
+}
+
+// ErrorsStream is the streaming counterpart of ErrorsUnary
+func ErrorsStream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return renderError(ss.Context(), info.FullMethod, handler(srv, ss))
+	}
+}
+
+func renderError(ctx context.Context, fullMethod string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		appErr = apperrors.From(err)
+	}
+	if appErr.GrpcCode == codes.Internal || appErr.GrpcCode == codes.Unknown {
+		log := logger.SugaredLogger().WithContextCorrelationId(ctx).With("package", "interceptors")
+		log.Errorw("Call failed with an internal error", "method", fullMethod, "error", err.Error())
+	}
+	correlationId, _ := ctx.Value(configuration.CorrelationIdKey).(string)
+	return appErr.Status(correlationId).Err()
+}
@@ -0,0 +1,66 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"my-microservice/limiter"
+)
+
+// LoadSheddingUnary rejects unary calls with Unavailable once the concurrency
+// limit of `l` is reached. Methods starting with one of `criticalMethods` are
+// never shed.
+func LoadSheddingUnary(l *limiter.Limiter, criticalMethods []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		release, ok := l.Acquire(methodPriority(info.FullMethod, criticalMethods))
+		if !ok {
+			return nil, status.Error(codes.Unavailable, "the server is overloaded, please retry later")
+		}
+
+		var err error
+		defer func() { release(outcome(err, limiter.OutcomeSuccess)) }()
+
+		var resp interface{}
+		resp, err = handler(ctx, req)
+		return resp, err
+	}
+}
+
+// LoadSheddingStream is the streaming counterpart of LoadSheddingUnary. The slot
+// is held for the whole lifetime of the stream, but since stream durations say
+// nothing about server load, they do not influence the limit.
+func LoadSheddingStream(l *limiter.Limiter, criticalMethods []string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		release, ok := l.Acquire(methodPriority(info.FullMethod, criticalMethods))
+		if !ok {
+			return status.Error(codes.Unavailable, "the server is overloaded, please retry later")
+		}
+
+		var err error
+		defer func() { release(outcome(err, limiter.OutcomeIgnored)) }()
+
+		err = handler(srv, ss)
+		return err
+	}
+}
+
+func methodPriority(fullMethod string, criticalMethods []string) limiter.Priority {
+	for _, prefix := range criticalMethods {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return limiter.PriorityCritical
+		}
+	}
+	return limiter.PriorityNormal
+}
+
+func outcome(err error, fallback limiter.Outcome) limiter.Outcome {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return limiter.OutcomeDropped
+	}
+	return fallback
+}
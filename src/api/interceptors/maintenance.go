@@ -0,0 +1,46 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"my-microservice/health"
+)
+
+// MaintenanceUnary rejects unary calls with Unavailable while the maintenance
+// mode is active. Methods starting with one of `allowedMethods` are still served.
+func MaintenanceUnary(allowedMethods []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := checkMaintenance(info.FullMethod, allowedMethods); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// MaintenanceStream is the streaming counterpart of MaintenanceUnary. Streams
+// which are already open are not affected.
+func MaintenanceStream(allowedMethods []string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkMaintenance(info.FullMethod, allowedMethods); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func checkMaintenance(fullMethod string, allowedMethods []string) error {
+	if !health.Maintenance() {
+		return nil
+	}
+	for _, prefix := range allowedMethods {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unavailable, "the service is under maintenance, please retry later")
+}
@@ -0,0 +1,57 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/coderollers/go-logger"
+	goErrors "github.com/go-errors/errors"
+	otelCodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"my-microservice/metrics"
+)
+
+// errPanic is the error of the calls which panicked
+var errPanic = status.Error(codes.Internal, "an internal error has occurred")
+
+// RecoveryUnary recovers from panics in the handlers and interceptors, logs them
+// with their stack and fails the call with Internal instead of crashing the
+// process. It must be placed right after the CorrelationId and tracing
+// interceptors, so that the panic is recorded on the span of the call.
+func RecoveryUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(ctx, info.FullMethod, p)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStream is the streaming counterpart of RecoveryUnary
+func RecoveryStream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(ss.Context(), info.FullMethod, p)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func recovered(ctx context.Context, fullMethod string, p interface{}) error {
+	err := goErrors.Wrap(p, 3)
+	metrics.PanicsRecovered.WithLabelValues("grpc").Inc()
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(otelCodes.Error, "panic")
+
+	log := logger.SugaredLogger().WithContextCorrelationId(ctx).With("package", "interceptors")
+	log.Errorw("Panic recovered", "panic", err.Error(), "stack", string(err.Stack()), "method", fullMethod)
+	return errPanic
+}
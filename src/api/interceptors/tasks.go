@@ -0,0 +1,25 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"my-microservice/tasks"
+)
+
+// TasksUnary tracks every call as a task named after the full method
+func TasksUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		defer tasks.Track(ctx, "GRPC "+info.FullMethod)()
+		return handler(ctx, req)
+	}
+}
+
+// TasksStream tracks every stream as a task named after the full method
+func TasksStream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		defer tasks.Track(ss.Context(), "GRPC "+info.FullMethod)()
+		return handler(srv, ss)
+	}
+}
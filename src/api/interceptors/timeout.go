@@ -0,0 +1,48 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"my-microservice/configuration"
+)
+
+// TimeoutUnary puts the deadline configured for the called method on the
+// context. Deadlines supplied by the client through grpc-timeout are already
+// applied by the GRPC server and are only ever shortened, never extended.
+func TimeoutUnary() grpc.UnaryServerInterceptor {
+	conf := configuration.AppConfig()
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		timeout := time.Duration(conf.Timeouts.RouteTimeoutMs(info.FullMethod)) * time.Millisecond
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+
+		// context.WithTimeout keeps the earlier deadline if the client's is shorter
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// TimeoutStream is the streaming counterpart of TimeoutUnary. The deadline
+// covers the whole stream, not the individual messages, and is only applied to
+// methods listed in ROUTE_TIMEOUTS, as the default request timeout would cut
+// long-lived streams such as the health watch.
+func TimeoutStream() grpc.StreamServerInterceptor {
+	conf := configuration.AppConfig()
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		timeout := time.Duration(conf.Timeouts.RouteTimeoutsMs[info.FullMethod]) * time.Millisecond
+		if timeout <= 0 {
+			return handler(srv, ss)
+		}
+
+		ctx, cancel := context.WithTimeout(ss.Context(), timeout)
+		defer cancel()
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
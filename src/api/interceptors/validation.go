@@ -0,0 +1,41 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"my-microservice/api/validation"
+)
+
+// ValidationUnary rejects requests which break the rules declared with the
+// (validate.field) option in the proto files. The Errors interceptors render
+// the violations as InvalidArgument with a BadRequest detail.
+func ValidationUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := validation.Validate(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ValidationStream is the streaming counterpart of ValidationUnary. Each
+// message received from the client is validated.
+func ValidationStream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ServerStream: ss})
+	}
+}
+
+// validatingStream validates the messages received on a stream
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validation.Validate(m)
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/coderollers/go-logger"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+
+	grpcServices "my-microservice/api/grpc"
+	adminHandlers "my-microservice/api/handlers/admin"
+	debugHandlers "my-microservice/api/handlers/debug"
+	fallbackHandlers "my-microservice/api/handlers/fallback"
+	healthHandlers "my-microservice/api/handlers/health"
+	"my-microservice/api/interceptors"
+	"my-microservice/api/middleware"
+	"my-microservice/configuration"
+	"my-microservice/lifecycle"
+	"my-microservice/metrics"
+	"my-microservice/protos"
+)
+
+// SetupManagementGin creates the router of the management listener, serving the
+// operational endpoints which must not be exposed through the ingress
+func SetupManagementGin() (*gin.Engine, error) {
+	conf := configuration.AppConfig()
+	log := logger.SugaredLogger()
+
+	log.Debugf("Setting up management Gin")
+	if err := adminHandlers.RegisterValidations(); err != nil {
+		return nil, fmt.Errorf("cannot register the validation rules of the admin handlers: %w", err)
+	}
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	// The caller address is audit-logged, so it must not be taken from client headers
+	_ = router.SetTrustedProxies(nil)
+
+	router.Use(middleware.CorrelationId())
+	router.Use(middleware.Recovery())
+	if conf.Management.Token == "" {
+		log.Warnf("MANAGEMENT_TOKEN is not set, management endpoints are not protected!")
+	}
+	router.Use(middleware.BearerToken(conf.Management.Token, []string{"/healthz", "/readyz"}))
+	router.Use(middleware.MaxBodySize())
+
+	router.NoRoute(fallbackHandlers.NoRoute)
+	router.NoMethod(fallbackHandlers.NoMethod(router.Routes))
+
+	router.GET("/healthz", healthHandlers.LivenessGet)
+	router.GET("/readyz", healthHandlers.ReadinessGet)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.GET("/config", debugHandlers.ConfigGet)
+	router.GET("/debug/tasks", debugHandlers.TasksGet)
+	router.Any("/debug/pprof/*name", debugHandlers.Pprof)
+	router.GET("/loglevel", adminHandlers.LogLevelsGet)
+	router.PUT("/loglevel", adminHandlers.LogLevelPut)
+	router.DELETE("/loglevel", adminHandlers.LogLevelDelete)
+	router.PUT("/loglevel/:logger", adminHandlers.LogLevelPut)
+	router.DELETE("/loglevel/:logger", adminHandlers.LogLevelDelete)
+	router.GET("/maintenance", adminHandlers.MaintenanceGet)
+	router.PUT("/maintenance", adminHandlers.MaintenancePut)
+	// TEMPLATE: Add more management handlers
+
+	return router, nil
+}
+
+// SetupManagementGrpc creates the GRPC server of the management listener,
+// serving the admin services which must not be exposed through the ingress
+func SetupManagementGrpc() *grpc.Server {
+	conf := configuration.AppConfig()
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(interceptors.CorrelationIdUnary(), interceptors.RecoveryUnary(), interceptors.ErrorsUnary(), interceptors.BearerTokenUnary(conf.Management.Token)),
+	)
+	protos.RegisterLoggingServer(grpcServer, &grpcServices.LoggingService{})
+	protos.RegisterMaintenanceServer(grpcServer, &grpcServices.MaintenanceService{})
+	// TEMPLATE: Register more admin GRPC services
+
+	return grpcServer
+}
+
+// ManagementServerComponent serves the management router and GRPC server on the
+// management port. It has no dependencies, so that the probes work while the
+// other components are starting up.
+func ManagementServerComponent(router *gin.Engine, grpcServer *grpc.Server) lifecycle.Component {
+	conf := configuration.AppConfig()
+	log := logger.SugaredLogger()
+
+	var srv *http.Server
+
+	return lifecycle.Component{
+		Name: "management-server",
+		Start: func(ctx context.Context) error {
+			srv = newHardenedServer(h2c.NewHandler(newHttpAndGrpcMux(router, grpcServer), newHardenedHttp2Server()))
+			listener, err := net.Listen("tcp", net.JoinHostPort(conf.Management.BindAddress, strconv.Itoa(int(conf.Management.Port))))
+			if err != nil {
+				return fmt.Errorf("cannot open listener socket: %w", err)
+			}
+
+			go func() {
+				log.Infof("Management endpoints listening on %s", listener.Addr().String())
+				if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					log.Fatalf("Unrecoverable management server failure: %s", err.Error())
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			err := srv.Shutdown(ctx)
+			// The admin RPCs are short, there is nothing worth draining
+			grpcServer.Stop()
+			return err
+		},
+	}
+}
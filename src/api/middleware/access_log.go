@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coderollers/go-logger"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+
+	"my-microservice/configuration"
+)
+
+// AccessLog writes a structured access log entry for every request, through
+// the logger named "accesslog". Server errors are logged as errors and slow
+// requests as warnings, everything else is sampled as configured.
+func AccessLog() gin.HandlerFunc {
+	conf := configuration.AppConfig()
+	slowThreshold := time.Duration(conf.AccessLog.SlowRequestThresholdMs) * time.Millisecond
+	skipPaths := make(map[string]bool, len(conf.AccessLog.SkipPaths))
+	for _, path := range conf.AccessLog.SkipPaths {
+		skipPaths[path] = true
+	}
+	redactHeaders := make(map[string]bool, len(conf.AccessLog.RedactHeaders))
+	for _, header := range conf.AccessLog.RedactHeaders {
+		redactHeaders[http.CanonicalHeaderKey(header)] = true
+	}
+
+	return func(c *gin.Context) {
+		if skipPaths[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		body := &countingReader{ReadCloser: c.Request.Body}
+		if c.Request.Body != nil {
+			c.Request.Body = body
+		}
+
+		write := func(status int) {
+			latency := time.Since(start)
+			bytesOut := c.Writer.Size()
+			if bytesOut < 0 {
+				bytesOut = 0
+			}
+			route := c.Request.Method + " " + c.FullPath()
+			slow := slowThreshold > 0 && latency > slowThreshold
+			if status < http.StatusInternalServerError && !slow {
+				if percent := conf.AccessLog.SamplePercentFor(route, status); percent < 100 && rand.Int31n(100) >= percent {
+					return
+				}
+			}
+
+			fields := []interface{}{
+				"method", c.Request.Method,
+				"route", c.FullPath(),
+				"path", c.Request.URL.Path,
+				"status", status,
+				"latency_ms", float64(latency) / float64(time.Millisecond),
+				"bytes_in", body.n,
+				"bytes_out", bytesOut,
+				"client_ip", c.ClientIP(),
+				"user_agent", c.Request.UserAgent(),
+			}
+			if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.HasTraceID() {
+				fields = append(fields, "trace_id", spanContext.TraceID().String())
+			}
+			if conf.AccessLog.LogHeaders {
+				fields = append(fields, "headers", redactedHeaders(c.Request.Header, redactHeaders))
+			}
+
+			log := logger.SugaredLogger().WithContextCorrelationId(c).With("package", "accesslog")
+			switch {
+			case status >= http.StatusInternalServerError:
+				log.Errorw("Request failed", fields...)
+			case slow:
+				log.Warnw("Slow request", fields...)
+			default:
+				log.Infow("Request served", fields...)
+			}
+		}
+
+		// Panics are recovered further up the chain, log them as the 500 they become
+		defer func() {
+			if p := recover(); p != nil {
+				write(http.StatusInternalServerError)
+				panic(p)
+			}
+		}()
+		c.Next()
+		write(c.Writer.Status())
+	}
+}
+
+func redactedHeaders(header http.Header, redact map[string]bool) map[string]string {
+	result := make(map[string]string, len(header))
+	for name, values := range header {
+		if redact[name] {
+			result[name] = "[REDACTED]"
+			continue
+		}
+		result[name] = strings.Join(values, ", ")
+	}
+	return result
+}
+
+// countingReader counts the bytes read from the request body
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
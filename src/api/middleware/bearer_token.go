@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/coderollers/go-utils"
+	"github.com/gin-gonic/gin"
+
+	"my-microservice/api/response"
+)
+
+// BearerToken rejects requests with 401 unless they carry `token` in the
+// Authorization header. Requests whose path is one of `publicPaths` are let
+// through without a token. If `token` is empty, all requests are let through.
+func BearerToken(token string, publicPaths []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.Next()
+			return
+		}
+		for _, path := range publicPaths {
+			if c.Request.URL.Path == path {
+				c.Next()
+				return
+			}
+		}
+
+		given, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", "Bearer")
+			response.FailureResponse(c, nil, utils.HttpError{
+				Code:    http.StatusUnauthorized,
+				Err:     errors.New("missing or invalid bearer token"),
+				Message: "Authentication required",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
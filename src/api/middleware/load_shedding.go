@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/coderollers/go-utils"
+	"github.com/gin-gonic/gin"
+
+	"my-microservice/api/response"
+	"my-microservice/limiter"
+)
+
+// LoadShedding rejects requests with 503 once the concurrency limit of `l` is
+// reached, instead of letting them queue up. Requests whose path starts with one
+// of `criticalPaths` are never shed.
+func LoadShedding(l *limiter.Limiter, criticalPaths []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		priority := limiter.PriorityNormal
+		for _, prefix := range criticalPaths {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				priority = limiter.PriorityCritical
+				break
+			}
+		}
+
+		release, ok := l.Acquire(priority)
+		if !ok {
+			c.Header("Retry-After", "1")
+			response.FailureResponse(c, nil, utils.HttpError{
+				Code:    http.StatusServiceUnavailable,
+				Err:     errors.New("concurrency limit reached"),
+				Message: "The server is overloaded, please retry later",
+			})
+			c.Abort()
+			return
+		}
+
+		// Released in a deferred call so that panicking handlers don't leak slots
+		defer func() {
+			switch c.Writer.Status() {
+			case http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+				release(limiter.OutcomeDropped)
+			default:
+				release(limiter.OutcomeSuccess)
+			}
+		}()
+
+		c.Next()
+	}
+}
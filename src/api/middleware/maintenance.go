@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/coderollers/go-utils"
+	"github.com/gin-gonic/gin"
+
+	"my-microservice/api/response"
+	"my-microservice/configuration"
+	"my-microservice/health"
+)
+
+// Maintenance rejects requests with 503 while the maintenance mode is active.
+// Requests whose path starts with one of the allowed paths are still served.
+func Maintenance() gin.HandlerFunc {
+	conf := configuration.AppConfig()
+	retryAfter := strconv.Itoa(int(conf.Maintenance.RetryAfterSec))
+
+	return func(c *gin.Context) {
+		if !health.Maintenance() {
+			c.Next()
+			return
+		}
+		for _, prefix := range conf.Maintenance.AllowedPaths {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		c.Header("Retry-After", retryAfter)
+		response.FailureResponse(c, nil, utils.HttpError{
+			Code:    http.StatusServiceUnavailable,
+			Err:     errors.New("maintenance mode is active"),
+			Message: "The service is under maintenance, please retry later",
+		})
+		c.Abort()
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"my-microservice/api/response"
+	"my-microservice/apperrors"
+	"my-microservice/configuration"
+)
+
+// MaxBodySize limits the size of request bodies to the maximum configured for
+// the route. Requests announcing a larger Content-Length are rejected with 413
+// right away, while bodies sent without a length fail when read past the limit.
+func MaxBodySize() gin.HandlerFunc {
+	conf := configuration.AppConfig()
+
+	return func(c *gin.Context) {
+		limit := conf.HttpServer.MaxBodyBytesFor(c.Request.Method + " " + c.FullPath())
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			response.ErrorResponse(c, nil, apperrors.ErrPayloadTooLarge.
+				Wrap(fmt.Errorf("request body of %d bytes exceeds the limit of %d bytes", c.Request.ContentLength, limit)).
+				WithMetadata("limit_bytes", strconv.FormatInt(limit, 10)))
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"syscall"
+
+	"github.com/coderollers/go-logger"
+	"github.com/coderollers/go-utils"
+	"github.com/gin-gonic/gin"
+	goErrors "github.com/go-errors/errors"
+	otelCodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"my-microservice/api/response"
+	"my-microservice/metrics"
+)
+
+// Recovery recovers from panics in the handlers and middleware, logs them with
+// their stack and answers with a 500 FailureResponse. It must be placed right
+// after the CorrelationId middleware, so that nothing else can crash the
+// process. As the span of the request has ended by then, TracePanics marks it.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				// Deliberate abort, let the HTTP server handle it
+				panic(p)
+			}
+
+			err := goErrors.Wrap(p, 2)
+			metrics.PanicsRecovered.WithLabelValues("http").Inc()
+
+			log := logger.SugaredLogger().WithContextCorrelationId(c).With("package", "middleware")
+			if isBrokenConnection(err.Err) {
+				// The client is gone, there is nobody to respond to
+				log.Warnw("Connection broken while serving the request", "error", err.Error(), "path", c.Request.URL.Path)
+				c.Abort()
+				return
+			}
+			log.Errorw("Panic recovered", "panic", err.Error(), "stack", string(err.Stack()), "path", c.Request.URL.Path)
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			response.FailureResponse(c, nil, utils.HttpError{
+				Code:    http.StatusInternalServerError,
+				Err:     err,
+				Message: "An internal error has occurred",
+			})
+			c.Abort()
+		}()
+		c.Next()
+	}
+}
+
+// TracePanics marks the span of the request as errored when a handler panics,
+// and lets the panic through to Recovery. It must be placed right after the
+// tracing middleware.
+func TracePanics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if p := recover(); p != nil {
+				span := trace.SpanFromContext(c.Request.Context())
+				span.RecordError(fmt.Errorf("panic: %v", p))
+				span.SetStatus(otelCodes.Error, "panic")
+				panic(p)
+			}
+		}()
+		c.Next()
+	}
+}
+
+func isBrokenConnection(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"my-microservice/tasks"
+)
+
+// Tasks tracks every request as a task named after its method and route
+func Tasks() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		defer tasks.Track(c, "HTTP "+c.Request.Method+" "+route)()
+		c.Next()
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/coderollers/go-utils"
+	"github.com/gin-gonic/gin"
+
+	"my-microservice/api/response"
+	"my-microservice/configuration"
+	"my-microservice/deadline"
+)
+
+// Timeout puts a deadline on the request context, based on the timeout
+// configured for the route and shortened by any client supplied deadline. Handlers
+// must pass `c.Request.Context()` on to anything that blocks. If the deadline
+// expires before the handler has started writing its response, a 504 is returned
+// and anything the handler writes afterwards is discarded. The handler is not
+// interrupted, so the 504 is only sent once it returns.
+func Timeout() gin.HandlerFunc {
+	conf := configuration.AppConfig()
+
+	return func(c *gin.Context) {
+		timeout := time.Duration(conf.Timeouts.RouteTimeoutMs(c.Request.Method+" "+c.FullPath())) * time.Millisecond
+		// A client deadline can only shorten the configured timeout, never remove it
+		if clientTimeout, ok := deadline.FromRequest(c.Request); ok && clientTimeout > 0 && (timeout <= 0 || clientTimeout < timeout) {
+			timeout = clientTimeout
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		writer := &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+		c.Writer = writer
+
+		c.Next()
+
+		c.Writer = writer.ResponseWriter
+		if !c.Writer.Written() && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			// The handler may have set headers for the response it failed to send
+			c.Writer.Header().Del("Content-Length")
+			response.FailureResponse(c, nil, utils.HttpError{
+				Code:    http.StatusGatewayTimeout,
+				Err:     ctx.Err(),
+				Message: "The request did not complete within " + timeout.String(),
+			})
+		}
+	}
+}
+
+// timeoutWriter discards the responses written after the deadline, so that the
+// late responses are replaced with a 504 instead of reaching the client
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx context.Context
+}
+
+// timedOut reports whether the response must be discarded, which is the case
+// when the deadline has expired before anything was sent
+func (w *timeoutWriter) timedOut() bool {
+	return !w.ResponseWriter.Written() && errors.Is(w.ctx.Err(), context.DeadlineExceeded)
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if w.timedOut() {
+		return 0, http.ErrHandlerTimeout
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	if w.timedOut() {
+		return 0, http.ErrHandlerTimeout
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	if w.timedOut() {
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *timeoutWriter) Flush() {
+	if w.timedOut() {
+		return
+	}
+	w.ResponseWriter.Flush()
+}
@@ -1,29 +1,53 @@
 package models
 
-// JSONSuccessResult represents the model of a synchronous call result
-type JSONSuccessResult struct {
-	Code          int         `json:"code" example:"200"`
-	Message       string      `json:"message,omitempty" example:"Success"`
-	Data          interface{} `json:"data,omitempty"`
-	CorrelationId string      `json:"correlation_id,omitempty" example:"705e4dcb-3ecd-24f3-3a35-3e926e4bded5"`
+// JSONSuccessResult represents the model of a synchronous call result. T is the
+// type of Data, so that each endpoint documents its own payload, such as
+// models.JSONSuccessResult[v1.GreetingResponse].
+type JSONSuccessResult[T any] struct {
+	Code          int    `json:"code" example:"200"`
+	Message       string `json:"message,omitempty" example:"Success"`
+	Data          T      `json:"data,omitempty"`
+	CorrelationId string `json:"correlation_id,omitempty" example:"705e4dcb-3ecd-24f3-3a35-3e926e4bded5"`
 }
 
-// JSONAcceptedResult represents the model of an async call result. Requires implementation of state machine
-type JSONAcceptedResult struct {
-	Code          int         `json:"code" example:"202"`
-	Id            string      `json:"id" example:"123-456-789-abc-def"`
-	Message       string      `json:"message,omitempty" example:"Accepted"`
-	Data          interface{} `json:"data,omitempty"`
-	CorrelationId string      `json:"correlation_id,omitempty" example:"705e4dcb-3ecd-24f3-3a35-3e926e4bded5"`
+// JSONAcceptedResult represents the model of an async call result. Id identifies the job doing the work, whose
+// state is polled at the URL of the Location header.
+type JSONAcceptedResult[T any] struct {
+	Code          int    `json:"code" example:"202"`
+	Id            string `json:"id" example:"123-456-789-abc-def"`
+	Message       string `json:"message,omitempty" example:"Accepted"`
+	Data          T      `json:"data,omitempty"`
+	CorrelationId string `json:"correlation_id,omitempty" example:"705e4dcb-3ecd-24f3-3a35-3e926e4bded5"`
+}
+
+// JSONPageResult represents the model of a call result which is a page of a list
+type JSONPageResult[T any] struct {
+	Code          int     `json:"code" example:"200"`
+	Message       string  `json:"message,omitempty" example:"Success"`
+	Data          Page[T] `json:"data"`
+	CorrelationId string  `json:"correlation_id,omitempty" example:"705e4dcb-3ecd-24f3-3a35-3e926e4bded5"`
+}
+
+// Page is a page of a list. The next page is requested with NextPageToken,
+// which is empty on the last page.
+type Page[T any] struct {
+	Items         []T    `json:"items"`
+	NextPageToken string `json:"next_page_token,omitempty" example:"eyJvZmZzZXQiOjUwfQ"`
+	// TotalSize is the size of the whole list, if it is cheap to compute
+	TotalSize *int64 `json:"total_size,omitempty" example:"120"`
 }
 
 // JSONFailureResult represents the model of a call result for a request which was deemed inappropriate by the server
 type JSONFailureResult struct {
-	Code          int         `json:"code" example:"400"`
-	Data          interface{} `json:"data,omitempty"`
-	Error         string      `json:"error,omitempty" example:"There was an error processing the request"`
-	Stack         string      `json:"stacktrace,omitempty"`
-	CorrelationId string      `json:"correlation_id,omitempty" example:"705e4dcb-3ecd-24f3-3a35-3e926e4bded5"`
+	Code          int               `json:"code" example:"400"`
+	ErrorCode     string            `json:"error_code,omitempty" example:"INVALID_ARGUMENT"`
+	Message       string            `json:"message,omitempty" example:"The request is invalid"`
+	Retryable     bool              `json:"retryable,omitempty" example:"false"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	Data          interface{}       `json:"data,omitempty"`
+	Error         string            `json:"error,omitempty" example:"There was an error processing the request"`
+	Stack         string            `json:"stacktrace,omitempty"`
+	CorrelationId string            `json:"correlation_id,omitempty" example:"705e4dcb-3ecd-24f3-3a35-3e926e4bded5"`
 }
 
 // JSONNotFoundResult represents the model of a call result for a request which references a missing object
@@ -0,0 +1,31 @@
+package models
+
+import "my-microservice/apperrors"
+
+// ProblemDetails represents an RFC 9457 problem, the alternative shape of
+// JSONFailureResult served as application/problem+json
+type ProblemDetails struct {
+	Type     string `json:"type" example:"about:blank"`
+	Title    string `json:"title" example:"Bad Request"`
+	Status   int    `json:"status" example:"400"`
+	Detail   string `json:"detail,omitempty" example:"The request is invalid"`
+	Instance string `json:"instance,omitempty" example:"/v1/"`
+
+	// Extension members
+	CorrelationId string            `json:"correlation_id,omitempty" example:"705e4dcb-3ecd-24f3-3a35-3e926e4bded5"`
+	ErrorCode     string            `json:"error_code,omitempty" example:"INVALID_ARGUMENT"`
+	Retryable     bool              `json:"retryable,omitempty" example:"false"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	Errors        []FieldViolation  `json:"errors,omitempty"`
+	Data          interface{}       `json:"data,omitempty"`
+	Error         string            `json:"error,omitempty" example:"There was an error processing the request"`
+	Stack         string            `json:"stacktrace,omitempty"`
+}
+
+// ValidationErrors is the Data of a failure caused by invalid request fields
+type ValidationErrors struct {
+	Errors []FieldViolation `json:"errors"`
+}
+
+// FieldViolation describes a request field which breaks a validation rule
+type FieldViolation = apperrors.FieldViolation
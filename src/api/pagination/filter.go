@@ -0,0 +1,191 @@
+package pagination
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operator is a comparison operator of a filter condition
+type Operator string
+
+const (
+	Equal          Operator = "="
+	NotEqual       Operator = "!="
+	Less           Operator = "<"
+	LessOrEqual    Operator = "<="
+	Greater        Operator = ">"
+	GreaterOrEqual Operator = ">="
+	Has            Operator = ":"
+)
+
+// operators are sorted so that the longest operators are matched first
+var operators = []Operator{NotEqual, LessOrEqual, GreaterOrEqual, Equal, Less, Greater, Has}
+
+// Condition is a condition of a filter. A filter is a list of conditions joined
+// with AND, a subset of https://google.aip.dev/160:
+//
+//	state = running AND progress >= 50 AND name:"greeting"
+//
+// Values containing spaces must be quoted. The ":" operator tells whether a
+// string contains the value, case-insensitively.
+type Condition struct {
+	Field    string
+	Operator Operator
+	// Value is a string, a float64, a bool or a time.Time, after the type of the field
+	Value interface{}
+}
+
+func (c Condition) String() string {
+	value := c.Value
+	if t, ok := value.(time.Time); ok {
+		value = t.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%s %s %q", c.Field, c.Operator, fmt.Sprint(value))
+}
+
+// Match tells whether a field value satisfies the condition. The value can be a
+// string, any integer or float, a bool, a time.Time, or a pointer to one of
+// these; nil values only satisfy !=.
+func (c Condition) Match(value interface{}) bool {
+	if c.Operator == Has {
+		s, ok := normalize(value).(string)
+		return ok && strings.Contains(strings.ToLower(s), strings.ToLower(c.Value.(string)))
+	}
+	cmp, ok := compare(normalize(value), c.Value)
+	if !ok {
+		return c.Operator == NotEqual
+	}
+	switch c.Operator {
+	case Equal:
+		return cmp == 0
+	case NotEqual:
+		return cmp != 0
+	case Less:
+		return cmp < 0
+	case LessOrEqual:
+		return cmp <= 0
+	case Greater:
+		return cmp > 0
+	case GreaterOrEqual:
+		return cmp >= 0
+	}
+	return false
+}
+
+// parseFilter parses a filter against the fields which can be filtered on
+func parseFilter(filter string, allowed map[string]FieldType) ([]Condition, error) {
+	var conditions []Condition
+	rest := strings.TrimSpace(filter)
+	for rest != "" {
+		if len(conditions) > 0 {
+			next, ok := strings.CutPrefix(rest, "AND ")
+			if !ok {
+				return nil, fmt.Errorf("conditions must be joined with AND, at %q", rest)
+			}
+			rest = strings.TrimSpace(next)
+		}
+
+		var condition Condition
+		var err error
+		condition.Field, condition.Operator, rest, err = cutFieldAndOperator(rest)
+		if err != nil {
+			return nil, err
+		}
+		fieldType, ok := allowed[condition.Field]
+		if !ok {
+			return nil, fmt.Errorf("cannot filter on %q, the allowed fields are %s", condition.Field, strings.Join(sortedKeys(allowed), ", "))
+		}
+		var value string
+		if value, rest, err = cutValue(strings.TrimSpace(rest)); err != nil {
+			return nil, err
+		}
+		if condition.Value, err = parseValue(condition.Field, fieldType, condition.Operator, value); err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+		rest = strings.TrimSpace(rest)
+	}
+	return conditions, nil
+}
+
+func cutFieldAndOperator(s string) (field string, operator Operator, rest string, err error) {
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r == '_' || r == '.' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	if end <= 0 {
+		return "", "", "", fmt.Errorf("expected a field name at %q", s)
+	}
+	field, rest = s[:end], strings.TrimSpace(s[end:])
+	for _, op := range operators {
+		if strings.HasPrefix(rest, string(op)) {
+			return field, op, rest[len(op):], nil
+		}
+	}
+	return "", "", "", fmt.Errorf("expected an operator after %q", field)
+}
+
+// cutValue cuts a quoted string, or a word which ends with a space
+func cutValue(s string) (value, rest string, err error) {
+	if strings.HasPrefix(s, `"`) {
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				value, err := strconv.Unquote(s[:i+1])
+				return value, s[i+1:], err
+			}
+		}
+		return "", "", fmt.Errorf("unterminated string at %q", s)
+	}
+	end := strings.IndexAny(s, " \t")
+	if end < 0 {
+		end = len(s)
+	}
+	if end == 0 {
+		return "", "", fmt.Errorf("expected a value")
+	}
+	return s[:end], s[end:], nil
+}
+
+func parseValue(field string, fieldType FieldType, operator Operator, value string) (interface{}, error) {
+	if operator == Has && fieldType != String {
+		return nil, fmt.Errorf("the : operator only applies to text fields, not to %q", field)
+	}
+	switch fieldType {
+	case Number:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q must be compared with a number", field)
+		}
+		return n, nil
+	case Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil || (operator != Equal && operator != NotEqual) {
+			return nil, fmt.Errorf("%q must be compared with = or != to true or false", field)
+		}
+		return b, nil
+	case Time:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, fmt.Errorf("%q must be compared with an RFC 3339 time, such as 2006-01-02T15:04:05Z", field)
+		}
+		return t, nil
+	}
+	if operator != Equal && operator != NotEqual && operator != Has {
+		return nil, fmt.Errorf("%q must be compared with =, != or :", field)
+	}
+	return value, nil
+}
+
+func sortedKeys(m map[string]FieldType) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,145 @@
+package pagination
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"my-microservice/api/models"
+)
+
+// Apply filters, sorts and pages a list held in memory. `value` returns the
+// value of a field of an item, for the fields of Options.Sort and
+// Options.Filter. Lists stored in a database should rather translate the query
+// into the query language of the database, and build the page with NewPage.
+//
+//	page := pagination.Apply(list, query, func(job jobs.Job, field string) interface{} {
+//		switch field {
+//		case "name":
+//			return job.Name
+//		case "created_at":
+//			return job.CreatedAt
+//		}
+//		return nil
+//	})
+func Apply[T any](items []T, query Query, value func(item T, field string) interface{}) models.Page[T] {
+	matching := make([]T, 0, len(items))
+	for _, item := range items {
+		if matches(item, query.Filter, value) {
+			matching = append(matching, item)
+		}
+	}
+
+	if len(query.Sort) > 0 {
+		sort.SliceStable(matching, func(i, j int) bool {
+			for _, order := range query.Sort {
+				cmp, _ := compare(normalize(value(matching[i], order.Field)), normalize(value(matching[j], order.Field)))
+				if cmp != 0 {
+					return (cmp < 0) != order.Desc
+				}
+			}
+			return false
+		})
+	}
+
+	start := query.Offset
+	if start > len(matching) {
+		start = len(matching)
+	}
+	end := start + query.PageSize
+	if end > len(matching) {
+		end = len(matching)
+	}
+	return NewPage(query, matching[start:end], len(matching))
+}
+
+func matches[T any](item T, conditions []Condition, value func(item T, field string) interface{}) bool {
+	for _, condition := range conditions {
+		if !condition.Match(value(item, condition.Field)) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalize converts a field value into a string, a float64, a bool, a
+// time.Time or nil
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, string, float64, bool, time.Time:
+		return v
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	}
+	if t, ok := rv.Interface().(time.Time); ok {
+		return t
+	}
+	return nil
+}
+
+// compare compares two normalized values of the same type. nil values come
+// first, and are not comparable to the others.
+func compare(a, b interface{}) (cmp int, ok bool) {
+	switch {
+	case a == nil && b == nil:
+		return 0, true
+	case a == nil:
+		return -1, false
+	case b == nil:
+		return 1, false
+	}
+
+	switch a := a.(type) {
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), true
+		}
+	case float64:
+		if b, ok := b.(float64); ok {
+			return compareOrdered(a < b, a > b), true
+		}
+	case bool:
+		if b, ok := b.(bool); ok {
+			return compareOrdered(!a && b, a && !b), true
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return compareOrdered(a.Before(b), a.After(b)), true
+		}
+	}
+	return 0, false
+}
+
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,213 @@
+package pagination
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"my-microservice/api/models"
+	"my-microservice/apperrors"
+	"my-microservice/configuration"
+)
+
+// FieldType is the type of a field which can be filtered on
+type FieldType int
+
+const (
+	// String fields support the =, != and : (contains) operators
+	String FieldType = iota
+	// Number fields support the =, !=, <, <=, > and >= operators
+	Number
+	// Bool fields support the = and != operators, with true or false
+	Bool
+	// Time fields support the same operators as numbers, with RFC 3339 values
+	Time
+)
+
+// Options describes what a list endpoint supports. The field names are the
+// ones clients use, such as the JSON names of the listed items.
+type Options struct {
+	// Sort lists the fields the list can be sorted by
+	Sort []string
+	// Filter lists the fields the list can be filtered on, with their types
+	Filter map[string]FieldType
+	// DefaultSort is the order of the list when the client does not ask for
+	// one, such as "created_at desc"
+	DefaultSort string
+	// DefaultPageSize and MaxPageSize override the ones of CPagination when set
+	DefaultPageSize int
+	MaxPageSize     int
+}
+
+// Order is a sort key
+type Order struct {
+	Field string
+	Desc  bool
+}
+
+func (o Order) String() string {
+	if o.Desc {
+		return o.Field + " desc"
+	}
+	return o.Field
+}
+
+// Query is a parsed list request
+type Query struct {
+	// PageSize is the number of items of the page, between 1 and the maximum
+	// page size
+	PageSize int
+	// Offset is the position of the first item of the page in the list
+	Offset int
+	// Sort lists the sort keys, most significant first
+	Sort []Order
+	// Filter lists the conditions the items must all satisfy
+	Filter []Condition
+}
+
+// FromQuery parses the `page_size`, `page_token`, `sort` and `filter` query
+// parameters of a list endpoint:
+//
+//	GET /v1/jobs?page_size=20&sort=-created_at,name&filter=state=running AND progress>=50
+//
+// `sort` is a comma separated list of fields, in descending order when prefixed
+// with "-" or followed by " desc". See Condition for the filter syntax. Invalid
+// parameters are reported as field violations of an apperrors.ErrInvalidArgument.
+func FromQuery(c *gin.Context, opts Options) (Query, error) {
+	var pageSize int64
+	if value := c.Query("page_size"); value != "" {
+		var err error
+		if pageSize, err = strconv.ParseInt(value, 10, 32); err != nil {
+			return Query{}, apperrors.ErrInvalidArgument.WithViolations(violation("page_size", "type", "page_size must be a number"))
+		}
+	}
+	return parse(opts, pageSize, c.Query("page_token"), c.Query("sort"), c.Query("filter"), "sort")
+}
+
+// FromRPC parses the standard fields of the GRPC list requests: page_size and
+// page_token (https://google.aip.dev/158), order_by (https://google.aip.dev/132)
+// and filter (https://google.aip.dev/160). The syntax is the same as the one of
+// FromQuery, and the page tokens work on both transports.
+func FromRPC(opts Options, pageSize int32, pageToken, orderBy, filter string) (Query, error) {
+	return parse(opts, int64(pageSize), pageToken, orderBy, filter, "order_by")
+}
+
+func parse(opts Options, pageSize int64, pageToken, sort, filter, sortParam string) (Query, error) {
+	conf := configuration.AppConfig()
+	defaultPageSize, maxPageSize := opts.DefaultPageSize, opts.MaxPageSize
+	if defaultPageSize == 0 {
+		defaultPageSize = int(conf.Pagination.DefaultPageSize)
+	}
+	if maxPageSize == 0 {
+		maxPageSize = int(conf.Pagination.MaxPageSize)
+	}
+
+	var (
+		query      Query
+		violations []apperrors.FieldViolation
+		err        error
+	)
+	switch {
+	case pageSize < 0:
+		violations = append(violations, violation("page_size", "gte", "page_size must not be negative"))
+	case pageSize == 0:
+		query.PageSize = defaultPageSize
+	case pageSize > int64(maxPageSize):
+		query.PageSize = maxPageSize
+	default:
+		query.PageSize = int(pageSize)
+	}
+	if sort == "" {
+		sort = opts.DefaultSort
+	}
+	if query.Sort, err = parseSort(sort, opts.Sort); err != nil {
+		violations = append(violations, violation(sortParam, "sort", fmt.Sprintf("%s is invalid: %s", sortParam, err.Error())))
+	}
+	if query.Filter, err = parseFilter(filter, opts.Filter); err != nil {
+		violations = append(violations, violation("filter", "filter", "filter is invalid: "+err.Error()))
+	}
+	if len(violations) > 0 {
+		return Query{}, apperrors.ErrInvalidArgument.WithViolations(violations...)
+	}
+
+	if pageToken != "" {
+		if query.Offset, err = decodeToken(pageToken, query.fingerprint()); err != nil {
+			return Query{}, apperrors.ErrInvalidArgument.WithViolations(violation("page_token", "token", "page_token "+err.Error()))
+		}
+	}
+	return query, nil
+}
+
+// NewPage returns the page of the list of `total` items which starts at the
+// query offset, with the token of the next page if there is one
+func NewPage[T any](query Query, items []T, total int) models.Page[T] {
+	size := int64(total)
+	page := models.Page[T]{Items: items, TotalSize: &size}
+	if next := query.Offset + len(items); len(items) > 0 && next < total {
+		page.NextPageToken = encodeToken(next, query.fingerprint())
+	}
+	return page
+}
+
+// fingerprint identifies the order and filter of the query, to which the page
+// tokens are bound
+func (q Query) fingerprint() string {
+	parts := make([]string, 0, len(q.Sort)+len(q.Filter))
+	for _, order := range q.Sort {
+		parts = append(parts, order.String())
+	}
+	for _, condition := range q.Filter {
+		parts = append(parts, condition.String())
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// parseSort parses a comma separated list of fields, each optionally prefixed
+// with "-" or followed by "asc" or "desc"
+func parseSort(sort string, allowed []string) ([]Order, error) {
+	var orders []Order
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(sort, ",") {
+		words := strings.Fields(item)
+		if len(words) == 0 {
+			if strings.TrimSpace(sort) == "" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("empty sort field")
+		}
+		order := Order{Field: words[0]}
+		if strings.HasPrefix(order.Field, "-") {
+			order.Field, order.Desc = order.Field[1:], true
+		}
+		if len(words) > 2 || (len(words) == 2 && (order.Desc || (words[1] != "asc" && words[1] != "desc"))) {
+			return nil, fmt.Errorf("%q must be a field either prefixed with - or followed by asc or desc", strings.TrimSpace(item))
+		}
+		if len(words) == 2 {
+			order.Desc = words[1] == "desc"
+		}
+		if !contains(allowed, order.Field) {
+			return nil, fmt.Errorf("cannot sort by %q, the allowed fields are %s", order.Field, strings.Join(allowed, ", "))
+		}
+		if seen[order.Field] {
+			return nil, fmt.Errorf("%q is listed more than once", order.Field)
+		}
+		seen[order.Field] = true
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+func violation(field, rule, message string) apperrors.FieldViolation {
+	return apperrors.FieldViolation{Field: field, Rule: rule, Message: message}
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
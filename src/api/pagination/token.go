@@ -0,0 +1,77 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+
+	"my-microservice/configuration"
+)
+
+var (
+	randomKey     []byte
+	randomKeyOnce sync.Once
+)
+
+// token is the content of a page token. The tokens are signed, and bound to the
+// order and filter of the query which issued them.
+type token struct {
+	Offset int    `json:"o"`
+	Query  string `json:"q"`
+}
+
+func encodeToken(offset int, fingerprint string) string {
+	payload, _ := json.Marshal(token{Offset: offset, Query: hash(fingerprint)})
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(sign(encoded))
+}
+
+func decodeToken(value, fingerprint string) (int, error) {
+	encoded, signature, ok := strings.Cut(value, ".")
+	decodedSignature, err := base64.RawURLEncoding.DecodeString(signature)
+	if !ok || err != nil || !hmac.Equal(decodedSignature, sign(encoded)) {
+		return 0, errors.New("is not a token returned by the previous page")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, errors.New("is not a token returned by the previous page")
+	}
+	var t token
+	if err := json.Unmarshal(payload, &t); err != nil || t.Offset < 0 {
+		return 0, errors.New("is not a token returned by the previous page")
+	}
+	if t.Query != hash(fingerprint) {
+		return 0, errors.New("was issued for a different sort or filter")
+	}
+	return t.Offset, nil
+}
+
+func sign(payload string) []byte {
+	mac := hmac.New(sha256.New, key())
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)[:16]
+}
+
+func hash(fingerprint string) string {
+	sum := sha256.Sum256([]byte(fingerprint))
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
+
+// key returns PAGE_TOKEN_KEY, or a random key if it is not set
+func key() []byte {
+	if configured := configuration.AppConfig().Pagination.TokenKey; configured != "" {
+		return []byte(configured)
+	}
+	randomKeyOnce.Do(func() {
+		randomKey = make([]byte, 32)
+		if _, err := rand.Read(randomKey); err != nil {
+			panic(err)
+		}
+	})
+	return randomKey
+}
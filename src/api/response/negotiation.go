@@ -0,0 +1,263 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+
+	"my-microservice/apperrors"
+)
+
+// Encoder writes the successful responses in a media type. Failures are always
+// rendered as JSON or problem details.
+type Encoder interface {
+	// CanEncode tells whether the payload given to the response helper, such as
+	// the data of SuccessResponse, can be written. Otherwise, the next accepted
+	// media type is tried.
+	CanEncode(data interface{}) bool
+	// Encode writes the response and its Content-Type. `result` is the
+	// envelope, such as a models.JSONSuccessResult, in which proto payloads were
+	// already converted to JSON with protojson.
+	Encode(c *gin.Context, code int, result, data interface{})
+}
+
+var (
+	// encoders maps the media types to their encoders, mediaTypes keeps the
+	// registration order, which decides between the matches of a wildcard
+	encoders   = make(map[string]Encoder)
+	mediaTypes []string
+	encodersMu sync.RWMutex
+)
+
+func init() {
+	RegisterEncoder(gin.MIMEJSON, jsonEncoder{})
+	for _, mediaType := range []string{"application/yaml", "application/x-yaml", "text/yaml"} {
+		RegisterEncoder(mediaType, yamlEncoder{})
+	}
+	for _, mediaType := range []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"} {
+		RegisterEncoder(mediaType, msgpackEncoder{})
+	}
+	for _, mediaType := range []string{"application/protobuf", "application/x-protobuf", "application/vnd.google.protobuf"} {
+		RegisterEncoder(mediaType, protobufEncoder{})
+	}
+}
+
+// RegisterEncoder makes the responses available in a media type, replacing
+// the encoder registered for it, if any. It must be called before serving
+// requests, typically from an init function.
+// TEMPLATE: Register the encoders of your media types
+func RegisterEncoder(mediaType string, encoder Encoder) {
+	mediaType = strings.ToLower(mediaType)
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	if _, ok := encoders[mediaType]; !ok {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	encoders[mediaType] = encoder
+}
+
+// negotiate writes `result` with the encoder of the media type the client
+// prefers, JSON by default, or answers with 406 if no accepted media type can
+// encode `data`.
+func negotiate(c *gin.Context, code int, result, data interface{}) {
+	encoder := selectEncoder(c.GetHeader("Accept"), data)
+	if encoder == nil {
+		encodersMu.RLock()
+		supported := strings.Join(mediaTypes, ", ")
+		encodersMu.RUnlock()
+		ErrorResponse(c, nil, apperrors.ErrNotAcceptable.WithMetadata("supported", supported))
+		return
+	}
+	// The representation depends on the Accept header, caches must know it
+	c.Writer.Header().Add("Vary", "Accept")
+	encoder.Encode(c, code, result, data)
+}
+
+// selectEncoder returns the encoder of the most preferred media type of the
+// Accept header which can encode `data`, or nil
+func selectEncoder(accept string, data interface{}) Encoder {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	for _, mediaRange := range parseAccept(accept) {
+		if mediaRange == "*/*" {
+			if encoder := encoders[gin.MIMEJSON]; encoder.CanEncode(data) {
+				return encoder
+			}
+		}
+		if prefix, ok := strings.CutSuffix(mediaRange, "*"); ok {
+			// A wildcard matches the media types in registration order
+			for _, mediaType := range mediaTypes {
+				if strings.HasPrefix(mediaType, strings.TrimPrefix(prefix, "*/")) && encoders[mediaType].CanEncode(data) {
+					return encoders[mediaType]
+				}
+			}
+			continue
+		}
+		if encoder, ok := encoders[mediaRange]; ok && encoder.CanEncode(data) {
+			return encoder
+		}
+	}
+	return nil
+}
+
+// parseAccept returns the media ranges of an Accept header, most preferred
+// first. An empty header accepts everything.
+func parseAccept(accept string) []string {
+	if strings.TrimSpace(accept) == "" {
+		return []string{"*/*"}
+	}
+	type mediaRange struct {
+		value   string
+		quality float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		value, params, _ := strings.Cut(part, ";")
+		r := mediaRange{value: strings.ToLower(strings.TrimSpace(value)), quality: 1}
+		for _, param := range strings.Split(params, ";") {
+			if name, q, ok := strings.Cut(strings.TrimSpace(param), "="); ok && strings.EqualFold(name, "q") {
+				if quality, err := strconv.ParseFloat(q, 64); err == nil {
+					r.quality = quality
+				}
+			}
+		}
+		if r.value != "" && r.quality > 0 {
+			ranges = append(ranges, r)
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+
+	values := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		values = append(values, r.value)
+	}
+	return values
+}
+
+// protoJSON converts a proto payload into JSON with protojson, which follows
+// the JSON mapping of the proto files. The boolean is false for other payloads.
+func protoJSON(data interface{}) (json.RawMessage, bool) {
+	m, ok := data.(proto.Message)
+	if !ok || !m.ProtoReflect().IsValid() {
+		return nil, false
+	}
+	encoded, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, false
+	}
+	return encoded, true
+}
+
+// jsonTree converts a result into maps, slices and scalars named after its
+// JSON representation, so that every media type uses the same field names
+func jsonTree(result interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return numbers(tree), nil
+}
+
+// numbers converts the json.Number values of a tree into int64 or float64
+func numbers(tree interface{}) interface{} {
+	switch value := tree.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			value[k] = numbers(v)
+		}
+	case []interface{}:
+		for i, v := range value {
+			value[i] = numbers(v)
+		}
+	case json.Number:
+		if n, err := value.Int64(); err == nil {
+			return n
+		}
+		n, _ := value.Float64()
+		return n
+	}
+	return tree
+}
+
+// jsonEncoder writes JSON, indented if the `pretty` query parameter is set
+type jsonEncoder struct{}
+
+func (jsonEncoder) CanEncode(interface{}) bool {
+	return true
+}
+
+func (jsonEncoder) Encode(c *gin.Context, code int, result, _ interface{}) {
+	if pretty, ok := c.GetQuery("pretty"); ok {
+		if enabled, err := strconv.ParseBool(pretty); pretty == "" || (err == nil && enabled) {
+			c.IndentedJSON(code, result)
+			return
+		}
+	}
+	c.JSON(code, result)
+}
+
+type yamlEncoder struct{}
+
+func (yamlEncoder) CanEncode(interface{}) bool {
+	return true
+}
+
+func (yamlEncoder) Encode(c *gin.Context, code int, result, _ interface{}) {
+	tree, err := jsonTree(result)
+	if err == nil {
+		var encoded []byte
+		if encoded, err = yaml.Marshal(tree); err == nil {
+			c.Data(code, "application/yaml; charset=utf-8", encoded)
+			return
+		}
+	}
+	_ = c.Error(err)
+	c.Status(http.StatusInternalServerError)
+}
+
+type msgpackEncoder struct{}
+
+func (msgpackEncoder) CanEncode(interface{}) bool {
+	return true
+}
+
+func (msgpackEncoder) Encode(c *gin.Context, code int, result, _ interface{}) {
+	tree, err := jsonTree(result)
+	if err != nil {
+		_ = c.Error(err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Render(code, render.MsgPack{Data: tree})
+}
+
+// protobufEncoder writes the payload alone, as the envelope has no proto
+// definition. The correlation ID is sent in the X-Correlation-ID header.
+type protobufEncoder struct{}
+
+func (protobufEncoder) CanEncode(data interface{}) bool {
+	_, ok := data.(proto.Message)
+	return ok
+}
+
+func (protobufEncoder) Encode(c *gin.Context, code int, _, data interface{}) {
+	c.Header("X-Correlation-ID", c.MustGet("correlation_id").(string))
+	c.Render(code, render.ProtoBuf{Data: data})
+}
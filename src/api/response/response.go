@@ -1,34 +1,98 @@
 package response
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"net/http"
+	"strings"
 
 	"github.com/coderollers/go-utils"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
 
 	"my-microservice/api/models"
+	"my-microservice/api/validation"
+	"my-microservice/apperrors"
 	"my-microservice/configuration"
 )
 
-func SuccessResponse(c *gin.Context, data interface{}) {
-	c.JSON(http.StatusOK, models.JSONSuccessResult{
+// ProblemContentType is the media type of RFC 9457 problem details
+const ProblemContentType = "application/problem+json"
+
+// SuccessResponse answers with `data` in the media type negotiated with
+// the Accept header. Proto payloads are rendered with protojson, or alone with
+// application/protobuf.
+func SuccessResponse[T any](c *gin.Context, data T) {
+	var result interface{} = models.JSONSuccessResult[T]{
 		Code:          http.StatusOK,
 		Data:          data,
 		Message:       "Success",
 		CorrelationId: c.MustGet("correlation_id").(string),
-	})
+	}
+	if encoded, ok := protoJSON(data); ok {
+		result = models.JSONSuccessResult[json.RawMessage]{
+			Code:          http.StatusOK,
+			Data:          encoded,
+			Message:       "Success",
+			CorrelationId: c.MustGet("correlation_id").(string),
+		}
+	}
+	negotiate(c, http.StatusOK, result, data)
 }
 
-func AcceptedResponse(c *gin.Context, id string, data interface{}) {
-	c.JSON(http.StatusAccepted, models.JSONAcceptedResult{
+// AcceptedResponse answers with 202 and `data`, negotiated like SuccessResponse.
+// If `location` is not empty, the Location header points to it.
+func AcceptedResponse[T any](c *gin.Context, id string, location string, data T) {
+	if location != "" {
+		c.Header("Location", location)
+	}
+	var result interface{} = models.JSONAcceptedResult[T]{
 		Code:          http.StatusAccepted,
 		Id:            id,
 		Data:          data,
 		Message:       "Accepted",
 		CorrelationId: c.MustGet("correlation_id").(string),
-	})
+	}
+	if encoded, ok := protoJSON(data); ok {
+		result = models.JSONAcceptedResult[json.RawMessage]{
+			Code:          http.StatusAccepted,
+			Id:            id,
+			Data:          encoded,
+			Message:       "Accepted",
+			CorrelationId: c.MustGet("correlation_id").(string),
+		}
+	}
+	negotiate(c, http.StatusAccepted, result, data)
+}
+
+// PageResponse answers with a page of a list, negotiated like SuccessResponse.
+// Pages can't be rendered as application/protobuf.
+func PageResponse[T any](c *gin.Context, page models.Page[T]) {
+	if page.Items == nil {
+		// An empty page has an empty list of items, not a null one
+		page.Items = []T{}
+	}
+	var result interface{} = models.JSONPageResult[T]{
+		Code:          http.StatusOK,
+		Data:          page,
+		Message:       "Success",
+		CorrelationId: c.MustGet("correlation_id").(string),
+	}
+	if _, ok := any(*new(T)).(proto.Message); ok {
+		items := make([]json.RawMessage, 0, len(page.Items))
+		for _, item := range page.Items {
+			encoded, _ := protoJSON(item)
+			items = append(items, encoded)
+		}
+		result = models.JSONPageResult[json.RawMessage]{
+			Code:          http.StatusOK,
+			Data:          models.Page[json.RawMessage]{Items: items, NextPageToken: page.NextPageToken, TotalSize: page.TotalSize},
+			Message:       "Success",
+			CorrelationId: c.MustGet("correlation_id").(string),
+		}
+	}
+	negotiate(c, http.StatusOK, result, page)
 }
 
 func FailureResponse(c *gin.Context, data interface{}, err utils.HttpError) {
@@ -41,8 +105,38 @@ func FailureResponse(c *gin.Context, data interface{}, err utils.HttpError) {
 		errorString = err.Error()
 		stackString = err.StackTrace()
 	}
-	c.JSON(err.Code, models.JSONFailureResult{
+	renderFailure(c, models.JSONFailureResult{
 		Code:          err.Code,
+		Message:       err.Message,
+		Data:          data,
+		Error:         errorString,
+		Stack:         stackString,
+		CorrelationId: c.MustGet("correlation_id").(string),
+	})
+}
+
+// ErrorResponse renders any error as a failure response, using the status code,
+// error code and public message of the apperrors.Error it is converted to. The
+// internal cause is only shown in Development mode. Field violations are
+// rendered as models.ValidationErrors, unless other data is given.
+func ErrorResponse(c *gin.Context, data interface{}, err error) {
+	appErr := apperrors.From(err)
+	if data == nil && len(appErr.Violations) > 0 {
+		data = models.ValidationErrors{Errors: appErr.Violations}
+	}
+	var errorString, stackString string
+	conf := configuration.AppConfig()
+	if conf.Development {
+		httpErr := utils.HttpError{Code: appErr.HttpStatus, Err: appErr}
+		errorString = appErr.Error()
+		stackString = httpErr.StackTrace()
+	}
+	renderFailure(c, models.JSONFailureResult{
+		Code:          appErr.HttpStatus,
+		ErrorCode:     appErr.Code,
+		Message:       appErr.Message,
+		Retryable:     appErr.Retryable,
+		Metadata:      appErr.Metadata,
 		Data:          data,
 		Error:         errorString,
 		Stack:         stackString,
@@ -51,9 +145,93 @@ func FailureResponse(c *gin.Context, data interface{}, err utils.HttpError) {
 }
 
 func NotFoundResponse(c *gin.Context, data interface{}) {
+	if wantsProblemDetails(c) {
+		renderProblemDetails(c, models.JSONFailureResult{
+			Code:          http.StatusNotFound,
+			Data:          data,
+			CorrelationId: c.MustGet("correlation_id").(string),
+		})
+		return
+	}
 	c.JSON(http.StatusNotFound, models.JSONNotFoundResult{
 		Code:          http.StatusNotFound,
 		Data:          data,
 		CorrelationId: c.MustGet("correlation_id").(string),
 	})
 }
+
+// MethodNotAllowedResponse answers with 405, listing the methods the route
+// supports in the Allow header
+func MethodNotAllowedResponse(c *gin.Context, allowed []string) {
+	c.Header("Allow", strings.Join(allowed, ", "))
+	FailureResponse(c, nil, utils.HttpError{
+		Code:    http.StatusMethodNotAllowed,
+		Err:     fmt.Errorf("method %s is not allowed, allowed methods are %s", c.Request.Method, strings.Join(allowed, ", ")),
+		Message: "The method is not allowed for this route",
+	})
+}
+
+// BindErrorResponse answers a request which could not be bound, with 413 if the
+// body exceeded the size limit and 400 with the field violations otherwise
+func BindErrorResponse(c *gin.Context, err error) {
+	ErrorResponse(c, nil, validation.FromBindError(err))
+}
+
+// renderFailure writes the failure with the JSONFailureResult envelope, or as
+// RFC 9457 problem details if the client or the configuration asks for them
+func renderFailure(c *gin.Context, result models.JSONFailureResult) {
+	if wantsProblemDetails(c) {
+		renderProblemDetails(c, result)
+		return
+	}
+	c.JSON(result.Code, result)
+}
+
+// renderProblemDetails writes the failure as RFC 9457 problem details
+func renderProblemDetails(c *gin.Context, result models.JSONFailureResult) {
+	problem := models.ProblemDetails{
+		Type:          "about:blank",
+		Title:         http.StatusText(result.Code),
+		Status:        result.Code,
+		Detail:        result.Message,
+		Instance:      c.Request.URL.Path,
+		CorrelationId: result.CorrelationId,
+		ErrorCode:     result.ErrorCode,
+		Retryable:     result.Retryable,
+		Metadata:      result.Metadata,
+		Error:         result.Error,
+		Stack:         result.Stack,
+	}
+	if typeBaseUri := configuration.AppConfig().Errors.TypeBaseUri; typeBaseUri != "" && result.ErrorCode != "" {
+		problem.Type = strings.TrimSuffix(typeBaseUri, "/") + "/" + strings.ToLower(strings.ReplaceAll(result.ErrorCode, "_", "-"))
+	}
+	if problem.Title == "" {
+		problem.Title = result.ErrorCode
+	}
+	// Validation errors are promoted to an extension member, any other data is kept as is
+	if validationErrors, ok := result.Data.(models.ValidationErrors); ok {
+		problem.Errors = validationErrors.Errors
+	} else {
+		problem.Data = result.Data
+	}
+
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(result.Code, problem)
+}
+
+// wantsProblemDetails tells whether failures are rendered as problem details.
+// An Accept header naming application/problem+json always selects them, one
+// naming application/json keeps the envelope, otherwise the configured
+// format is used.
+func wantsProblemDetails(c *gin.Context) bool {
+	// The shape of the failure depends on the Accept header, caches must know it
+	c.Writer.Header().Add("Vary", "Accept")
+	accept := c.GetHeader("Accept")
+	if strings.Contains(accept, ProblemContentType) {
+		return true
+	}
+	if strings.Contains(accept, gin.MIMEJSON) {
+		return false
+	}
+	return configuration.AppConfig().Errors.Format == configuration.ErrorFormatProblem
+}
@@ -5,55 +5,46 @@ import (
 	"time"
 
 	"github.com/coderollers/go-logger"
-	"github.com/coderollers/go-stats/concurrency"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
 	"my-microservice/configuration"
+	"my-microservice/lifecycle"
 	"my-microservice/tracer"
 )
 
-func StartTelemetry(ctx context.Context) {
-	concurrency.GlobalWaitGroup.Add(1)
-	defer concurrency.GlobalWaitGroup.Done()
-
+// TelemetryComponent sets up the tracer provider selected by JaegerEndpoint and
+// flushes the pending spans on shutdown.
+func TelemetryComponent() lifecycle.Component {
 	conf := configuration.AppConfig()
 	log := logger.SugaredLogger()
 
+	var tp *sdktrace.TracerProvider
+
 	// Set up telemetry
 	otTimeout := conf.CleanupTimeoutSec / 2
 	if conf.CleanupTimeoutSec > 10 {
 		otTimeout = 10
 	}
-	if conf.JaegerEndpoint == "stdout" {
-		log.Infof("Stdout Telemetry enabled")
-		tp, err := tracer.InitTracerStdout(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
 
-		defer func() {
-			concurrency.GlobalWaitGroup.Add(1)
-			defer concurrency.GlobalWaitGroup.Done()
-			localCtx, localCancel := context.WithTimeout(context.Background(), time.Duration(otTimeout)*time.Second)
-			defer localCancel()
-			if err := tp.Shutdown(localCtx); err != nil {
-				log.Errorf("Error shutting down tracer provider: %s", err.Error())
+	return lifecycle.Component{
+		Name: "telemetry",
+		Start: func(ctx context.Context) error {
+			var err error
+			if conf.JaegerEndpoint == "stdout" {
+				log.Infof("Stdout Telemetry enabled")
+				tp, err = tracer.InitTracerStdout(ctx)
+			} else if conf.JaegerEndpoint != "" {
+				log.Infof("Remote Telemetry enabled")
+				tp, err = tracer.InitTracerJaeger(ctx, conf.JaegerEndpoint, configuration.OTName)
 			}
-		}()
-	} else if conf.JaegerEndpoint != "" {
-		log.Infof("Remote Telemetry enabled")
-		tp, err := tracer.InitTracerJaeger(ctx, conf.JaegerEndpoint, configuration.OTName)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		defer func() {
-			concurrency.GlobalWaitGroup.Add(1)
-			defer concurrency.GlobalWaitGroup.Done()
-			localCtx, localCancel := context.WithTimeout(context.Background(), time.Duration(otTimeout)*time.Second)
-			defer localCancel()
-			if err := tp.Shutdown(localCtx); err != nil {
-				log.Errorf("Error shutting down tracer provider: %s", err.Error())
+			return err
+		},
+		Stop: func(ctx context.Context) error {
+			if tp == nil {
+				return nil
 			}
-		}()
+			return tp.Shutdown(ctx)
+		},
+		StopTimeout: time.Duration(otTimeout) * time.Second,
 	}
 }
@@ -0,0 +1,220 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"my-microservice/apperrors"
+	"my-microservice/protos"
+)
+
+var (
+	uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	// patterns caches the compiled `pattern` rules
+	patterns sync.Map
+)
+
+// ValidateProto checks the rules declared with the (validate.field) option in
+// the proto files, in `m` and in the messages it contains. The violations are
+// named after the proto field paths, such as "address.city" or "items[0].name".
+func ValidateProto(m proto.Message) []apperrors.FieldViolation {
+	var violations []apperrors.FieldViolation
+	validateMessage(m.ProtoReflect(), "", &violations)
+	return violations
+}
+
+func validateMessage(m protoreflect.Message, prefix string, violations *[]apperrors.FieldViolation) {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		path := prefix + string(fd.Name())
+		rules, _ := proto.GetExtension(fd.Options(), protos.E_Field).(*protos.FieldRules)
+
+		switch {
+		case fd.IsList():
+			validateList(m.Get(fd).List(), fd, rules, path, violations)
+		case fd.IsMap():
+			entries := m.Get(fd).Map()
+			if rules.GetRequired() && entries.Len() == 0 {
+				*violations = append(*violations, violation(path, "required", "", reflect.Map))
+			}
+			if fd.MapValue().Kind() == protoreflect.MessageKind {
+				entries.Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+					validateMessage(value.Message(), fmt.Sprintf("%s[%v].", path, key.Interface()), violations)
+					return true
+				})
+			}
+		default:
+			validateValue(m.Has(fd), m.Get(fd), fd, rules, path, violations)
+		}
+	}
+}
+
+func validateList(list protoreflect.List, fd protoreflect.FieldDescriptor, rules *protos.FieldRules, path string, violations *[]apperrors.FieldViolation) {
+	if list.Len() == 0 {
+		if rules.GetRequired() {
+			*violations = append(*violations, violation(path, "required", "", reflect.Slice))
+		}
+		return
+	}
+
+	repeated := rules.GetRepeated()
+	if repeated.GetMinItems() > 0 && uint64(list.Len()) < repeated.GetMinItems() {
+		*violations = append(*violations, violation(path, "min", strconv.FormatUint(repeated.GetMinItems(), 10), reflect.Slice))
+	}
+	if repeated != nil && repeated.MaxItems != nil && uint64(list.Len()) > repeated.GetMaxItems() {
+		*violations = append(*violations, violation(path, "max", strconv.FormatUint(repeated.GetMaxItems(), 10), reflect.Slice))
+	}
+	for i := 0; i < list.Len(); i++ {
+		item := list.Get(i)
+		validateValue(isItemSet(fd, item), item, fd, repeated.GetItems(), fmt.Sprintf("%s[%d]", path, i), violations)
+	}
+}
+
+// isItemSet tells whether a list item is set, as scalar items can't be absent.
+// Scalar items are compared with the zero value of their kind, since repeated
+// fields have no default value.
+func isItemSet(fd protoreflect.FieldDescriptor, item protoreflect.Value) bool {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return true
+	case protoreflect.BytesKind:
+		return len(item.Bytes()) > 0
+	case protoreflect.StringKind:
+		return item.String() != ""
+	case protoreflect.BoolKind:
+		return item.Bool()
+	case protoreflect.EnumKind:
+		return item.Enum() != 0
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return item.Float() != 0
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return item.Uint() != 0
+	}
+	return item.Int() != 0
+}
+
+// validateValue checks a singular value, or an item of a list. Unset values are
+// only checked by the `required` rule.
+func validateValue(set bool, value protoreflect.Value, fd protoreflect.FieldDescriptor, rules *protos.FieldRules, path string, violations *[]apperrors.FieldViolation) {
+	if !set {
+		if rules.GetRequired() {
+			*violations = append(*violations, violation(path, "required", "", reflect.Invalid))
+		}
+		return
+	}
+
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		validateMessage(value.Message(), path+".", violations)
+	case protoreflect.StringKind:
+		validateString(value.String(), rules.GetString_(), path, violations)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		validateInt(value.Int(), rules.GetInt(), path, violations)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n := value.Uint()
+		if n > math.MaxInt64 {
+			n = math.MaxInt64
+		}
+		validateInt(int64(n), rules.GetInt(), path, violations)
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		validateDouble(value.Float(), rules.GetDouble(), path, violations)
+	}
+}
+
+func validateString(value string, rules *protos.StringRules, path string, violations *[]apperrors.FieldViolation) {
+	if rules == nil {
+		return
+	}
+	length := uint64(utf8.RuneCountInString(value))
+	if rules.MinLen != nil && length < rules.GetMinLen() {
+		*violations = append(*violations, violation(path, "min", strconv.FormatUint(rules.GetMinLen(), 10), reflect.String))
+	}
+	if rules.MaxLen != nil && length > rules.GetMaxLen() {
+		*violations = append(*violations, violation(path, "max", strconv.FormatUint(rules.GetMaxLen(), 10), reflect.String))
+	}
+	if rules.Pattern != nil && !pattern(rules.GetPattern()).MatchString(value) {
+		*violations = append(*violations, violation(path, "pattern", rules.GetPattern(), reflect.String))
+	}
+	if rules.GetEmail() {
+		if address, err := mail.ParseAddress(value); err != nil || address.Address != value {
+			*violations = append(*violations, violation(path, "email", "", reflect.String))
+		}
+	}
+	if rules.GetUuid() && !uuidRegexp.MatchString(value) {
+		*violations = append(*violations, violation(path, "uuid", "", reflect.String))
+	}
+	if len(rules.GetIn()) > 0 {
+		allowed := false
+		for _, in := range rules.GetIn() {
+			allowed = allowed || in == value
+		}
+		if !allowed {
+			*violations = append(*violations, violation(path, "oneof", strings.Join(rules.GetIn(), " "), reflect.String))
+		}
+	}
+}
+
+func validateInt(value int64, rules *protos.IntRules, path string, violations *[]apperrors.FieldViolation) {
+	if rules == nil {
+		return
+	}
+	checks := []struct {
+		rule  string
+		limit *int64
+		ok    func(limit int64) bool
+	}{
+		{"gt", rules.Gt, func(limit int64) bool { return value > limit }},
+		{"gte", rules.Gte, func(limit int64) bool { return value >= limit }},
+		{"lt", rules.Lt, func(limit int64) bool { return value < limit }},
+		{"lte", rules.Lte, func(limit int64) bool { return value <= limit }},
+	}
+	for _, check := range checks {
+		if check.limit != nil && !check.ok(*check.limit) {
+			*violations = append(*violations, violation(path, check.rule, strconv.FormatInt(*check.limit, 10), reflect.Int64))
+		}
+	}
+}
+
+func validateDouble(value float64, rules *protos.DoubleRules, path string, violations *[]apperrors.FieldViolation) {
+	if rules == nil {
+		return
+	}
+	checks := []struct {
+		rule  string
+		limit *float64
+		ok    func(limit float64) bool
+	}{
+		{"gt", rules.Gt, func(limit float64) bool { return value > limit }},
+		{"gte", rules.Gte, func(limit float64) bool { return value >= limit }},
+		{"lt", rules.Lt, func(limit float64) bool { return value < limit }},
+		{"lte", rules.Lte, func(limit float64) bool { return value <= limit }},
+	}
+	for _, check := range checks {
+		if check.limit != nil && !check.ok(*check.limit) {
+			*violations = append(*violations, violation(path, check.rule, strconv.FormatFloat(*check.limit, 'g', -1, 64), reflect.Float64))
+		}
+	}
+}
+
+// pattern compiles a `pattern` rule once. Invalid patterns are programming
+// errors, which make the validation panic.
+func pattern(expr string) *regexp.Regexp {
+	if re, ok := patterns.Load(expr); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(expr)
+	patterns.Store(expr, re)
+	return re
+}
@@ -0,0 +1,179 @@
+package validation_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"my-microservice/api/validation"
+	"my-microservice/protos"
+)
+
+// testMessages builds the messages below, so that the rules can be tested
+// without generating code:
+//
+//	message Item {
+//	  string name = 1 [(validate.field).required = true];
+//	}
+//	message Request {
+//	  repeated string tags = 1 [(validate.field).repeated = {min_items: 1, items: {required: true, string: {max_len: 3}}}];
+//	  repeated int32 counts = 2 [(validate.field).repeated.items.required = true];
+//	  map<string, Item> items = 3 [(validate.field).required = true];
+//	  Item item = 4 [(validate.field).required = true];
+//	  repeated Item list = 5;
+//	}
+func testMessages(t *testing.T) (request, item protoreflect.MessageDescriptor) {
+	t.Helper()
+
+	withRules := func(rules *protos.FieldRules) *descriptorpb.FieldOptions {
+		options := &descriptorpb.FieldOptions{}
+		proto.SetExtension(options, protos.E_Field, rules)
+		return options
+	}
+	field := func(name string, number int32, label descriptorpb.FieldDescriptorProto_Label, kind descriptorpb.FieldDescriptorProto_Type, typeName string, rules *protos.FieldRules) *descriptorpb.FieldDescriptorProto {
+		fd := &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number), Label: label.Enum(), Type: kind.Enum()}
+		if typeName != "" {
+			fd.TypeName = proto.String(typeName)
+		}
+		if rules != nil {
+			fd.Options = withRules(rules)
+		}
+		return fd
+	}
+	const (
+		labelOptional = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		labelRepeated = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		typeString    = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		typeInt32     = descriptorpb.FieldDescriptorProto_TYPE_INT32
+		typeMessage   = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	)
+	required := &protos.FieldRules{Required: true}
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("validation_test.proto"),
+		Package:    proto.String("validationtest"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"validate.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name:  proto.String("Item"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("name", 1, labelOptional, typeString, "", required)},
+			},
+			{
+				Name: proto.String("Request"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("tags", 1, labelRepeated, typeString, "", &protos.FieldRules{Type: &protos.FieldRules_Repeated{Repeated: &protos.RepeatedRules{
+						MinItems: proto.Uint64(1),
+						Items:    &protos.FieldRules{Required: true, Type: &protos.FieldRules_String_{String_: &protos.StringRules{MaxLen: proto.Uint64(3)}}},
+					}}}),
+					field("counts", 2, labelRepeated, typeInt32, "", &protos.FieldRules{Type: &protos.FieldRules_Repeated{Repeated: &protos.RepeatedRules{Items: required}}}),
+					field("items", 3, labelRepeated, typeMessage, ".validationtest.Request.ItemsEntry", required),
+					field("item", 4, labelOptional, typeMessage, ".validationtest.Item", required),
+					field("list", 5, labelRepeated, typeMessage, ".validationtest.Item", nil),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{{
+					Name: proto.String("ItemsEntry"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						field("key", 1, labelOptional, typeString, "", nil),
+						field("value", 2, labelOptional, typeMessage, ".validationtest.Item", nil),
+					},
+					Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+				}},
+			},
+		},
+	}
+	fd, err := protodesc.NewFile(file, protoFiles{})
+	if err != nil {
+		t.Fatalf("cannot build the test messages: %s", err.Error())
+	}
+	return fd.Messages().ByName("Request"), fd.Messages().ByName("Item")
+}
+
+// protoFiles resolves the import of validate.proto
+type protoFiles struct{}
+
+func (protoFiles) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	return protos.File_validate_proto, nil
+}
+
+func (protoFiles) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	return nil, nil
+}
+
+func TestValidateProto(t *testing.T) {
+	requestDesc, itemDesc := testMessages(t)
+	newItem := func(name string) protoreflect.Value {
+		item := dynamicpb.NewMessage(itemDesc)
+		item.Set(itemDesc.Fields().ByName("name"), protoreflect.ValueOfString(name))
+		return protoreflect.ValueOfMessage(item)
+	}
+	field := func(name string) protoreflect.FieldDescriptor {
+		return requestDesc.Fields().ByName(protoreflect.Name(name))
+	}
+
+	tests := []struct {
+		name  string
+		build func(m *dynamicpb.Message)
+		want  []string
+	}{
+		{
+			name: "valid",
+			build: func(m *dynamicpb.Message) {
+				m.Mutable(field("tags")).List().Append(protoreflect.ValueOfString("a"))
+				m.Mutable(field("counts")).List().Append(protoreflect.ValueOfInt32(1))
+				m.Mutable(field("items")).Map().Set(protoreflect.ValueOfString("k").MapKey(), newItem("a"))
+				m.Set(field("item"), newItem("a"))
+				m.Mutable(field("list")).List().Append(newItem("a"))
+			},
+		},
+		{
+			// Empty values are only checked by `required`, not by min_items
+			name: "empty",
+			want: []string{"item:required", "items:required"},
+		},
+		{
+			name: "zero list items",
+			build: func(m *dynamicpb.Message) {
+				m.Mutable(field("tags")).List().Append(protoreflect.ValueOfString(""))
+				m.Mutable(field("tags")).List().Append(protoreflect.ValueOfString("long"))
+				m.Mutable(field("counts")).List().Append(protoreflect.ValueOfInt32(0))
+				m.Mutable(field("items")).Map().Set(protoreflect.ValueOfString("k").MapKey(), newItem("a"))
+				m.Set(field("item"), newItem("a"))
+			},
+			want: []string{"counts[0]:required", "tags[0]:required", "tags[1]:max"},
+		},
+		{
+			name: "nested messages",
+			build: func(m *dynamicpb.Message) {
+				m.Mutable(field("tags")).List().Append(protoreflect.ValueOfString("a"))
+				m.Mutable(field("items")).Map().Set(protoreflect.ValueOfString("k").MapKey(), newItem(""))
+				m.Set(field("item"), newItem(""))
+				m.Mutable(field("list")).List().Append(newItem(""))
+			},
+			want: []string{"item.name:required", "items[k].name:required", "list[0].name:required"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := dynamicpb.NewMessage(requestDesc)
+			if tt.build != nil {
+				tt.build(m)
+			}
+
+			var got []string
+			for _, violation := range validation.ValidateProto(m) {
+				got = append(got, violation.Field+":"+violation.Rule)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"my-microservice/apperrors"
+)
+
+// messages are the templates of the violation messages, by validation rule.
+// "{field}", "{param}" and "{rule}" are replaced with the field name, the rule
+// parameter and the rule name. Rules which depend on the kind of the field have ".string" and
+// ".items" variants for strings and collections.
+var (
+	messages = map[string]string{
+		"required":     "{field} is required",
+		"email":        "{field} must be a valid email address",
+		"url":          "{field} must be a valid URL",
+		"uri":          "{field} must be a valid URI",
+		"uuid":         "{field} must be a valid UUID",
+		"alpha":        "{field} must contain letters only",
+		"alphanum":     "{field} must contain letters and digits only",
+		"numeric":      "{field} must be numeric",
+		"oneof":        "{field} must be one of: {param}",
+		"len":          "{field} must be {param}",
+		"len.string":   "{field} must be {param} characters long",
+		"len.items":    "{field} must contain {param} items",
+		"min":          "{field} must be at least {param}",
+		"min.string":   "{field} must be at least {param} characters long",
+		"min.items":    "{field} must contain at least {param} items",
+		"max":          "{field} must be at most {param}",
+		"max.string":   "{field} must be at most {param} characters long",
+		"max.items":    "{field} must contain at most {param} items",
+		"eq":           "{field} must be equal to {param}",
+		"ne":           "{field} must not be equal to {param}",
+		"gt":           "{field} must be greater than {param}",
+		"gte":          "{field} must be greater than or equal to {param}",
+		"lt":           "{field} must be less than {param}",
+		"lte":          "{field} must be less than or equal to {param}",
+		"pattern":      "{field} must match the pattern {param}",
+		"type":         "{field} must be of type {param}",
+		"unrecognized": "{field} does not satisfy the {rule} rule",
+	}
+	messagesMu sync.RWMutex
+)
+
+func init() {
+	// Name the fields after their request names instead of their Go names. This
+	// must happen before the first validation, which caches the struct fields.
+	engine().RegisterTagNameFunc(fieldName)
+}
+
+// RegisterValidation registers a custom validation rule, usable in `binding`
+// struct tags, and the template of its violation message. It must be called
+// before serving requests, typically while setting up the router.
+func RegisterValidation(rule string, fn validator.Func, message string) error {
+	if err := engine().RegisterValidation(rule, fn); err != nil {
+		return err
+	}
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	messages[rule] = message
+	return nil
+}
+
+// Bind fills `obj` from the path parameters (`uri` tags), the query string
+// (`form` tags), the headers (`header` tags) and the JSON body, then validates
+// it. All field violations are reported at once, in an apperrors.Error which
+// ErrorResponse renders in the `errors` list of the failure data. Proto
+// messages are bound from the JSON body only, and checked against the rules
+// of their proto files.
+func Bind(c *gin.Context, obj interface{}) error {
+	if m, ok := obj.(proto.Message); ok {
+		if c.Request.ContentLength != 0 {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				return FromBindError(err)
+			}
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, m); err != nil {
+				return apperrors.ErrInvalidArgument.Wrap(err).WithMessage("The request body is not valid JSON")
+			}
+		}
+		return Validate(m)
+	}
+
+	// Gin maps untagged fields by their Go names, so only the sources the struct
+	// has tags for are bound
+	var bindings []func() error
+	if len(c.Params) > 0 && hasTag(obj, "uri") {
+		bindings = append(bindings, func() error { return c.ShouldBindUri(obj) })
+	}
+	if c.Request.URL.RawQuery != "" && hasTag(obj, "form") {
+		bindings = append(bindings, func() error { return c.ShouldBindQuery(obj) })
+	}
+	if hasTag(obj, "header") {
+		bindings = append(bindings, func() error { return c.ShouldBindHeader(obj) })
+	}
+	if c.Request.ContentLength != 0 {
+		bindings = append(bindings, func() error { return c.ShouldBindJSON(obj) })
+	}
+
+	// Every Gin binding also validates the struct, which fails as long as the
+	// other sources are not bound, so validation errors are only reported once
+	// all sources are bound
+	for _, bind := range bindings {
+		var validationErrs validator.ValidationErrors
+		if err := bind(); err != nil && !errors.As(err, &validationErrs) {
+			return FromBindError(err)
+		}
+	}
+	return Validate(obj)
+}
+
+// Validate checks the `binding` rules of a struct, or the rules declared in the
+// proto files of a proto message. It is useful for input which is not bound by
+// Gin, such as the requests of the GRPC services.
+func Validate(obj interface{}) error {
+	if m, ok := obj.(proto.Message); ok {
+		if violations := ValidateProto(m); len(violations) > 0 {
+			return apperrors.ErrInvalidArgument.WithViolations(violations...)
+		}
+		return nil
+	}
+	return FromBindError(binding.Validator.ValidateStruct(obj))
+}
+
+// FromBindError converts the errors of Gin bindings into apperrors: validation
+// errors and mistyped JSON fields become an ErrInvalidArgument with field
+// violations, oversized bodies an ErrPayloadTooLarge.
+func FromBindError(err error) error {
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+		syntaxErr      *json.SyntaxError
+		maxBytesErr    *http.MaxBytesError
+	)
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &validationErrs):
+		return apperrors.ErrInvalidArgument.Wrap(err).WithViolations(Violations(validationErrs)...)
+	case errors.As(err, &typeErr):
+		return apperrors.ErrInvalidArgument.Wrap(err).WithViolations(violation(typeErr.Field, "type", typeErr.Type.Kind().String(), reflect.Invalid))
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return apperrors.ErrInvalidArgument.Wrap(err).WithMessage("The request body is not valid JSON")
+	case errors.As(err, &maxBytesErr):
+		return apperrors.ErrPayloadTooLarge.Wrap(err).WithMetadata("limit_bytes", strconv.FormatInt(maxBytesErr.Limit, 10))
+	}
+	return apperrors.ErrInvalidArgument.Wrap(err).WithMessage("The request could not be parsed")
+}
+
+// Violations converts validation errors into field violations, named after the
+// field paths in the request, such as "address.city"
+func Violations(errs validator.ValidationErrors) []apperrors.FieldViolation {
+	violations := make([]apperrors.FieldViolation, 0, len(errs))
+	for _, fe := range errs {
+		field := fe.Namespace()
+		// Remove the name of the struct
+		if idx := strings.Index(field, "."); idx >= 0 {
+			field = field[idx+1:]
+		}
+		violations = append(violations, violation(field, fe.Tag(), fe.Param(), fe.Kind()))
+	}
+	return violations
+}
+
+func violation(field, rule, param string, kind reflect.Kind) apperrors.FieldViolation {
+	return apperrors.FieldViolation{
+		Field:   field,
+		Rule:    rule,
+		Message: message(field, rule, param, kind),
+	}
+}
+
+func message(field, rule, param string, kind reflect.Kind) string {
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+
+	var template string
+	switch kind {
+	case reflect.String:
+		template = messages[rule+".string"]
+	case reflect.Slice, reflect.Array, reflect.Map:
+		template = messages[rule+".items"]
+	}
+	if template == "" {
+		template = messages[rule]
+	}
+	if template == "" {
+		template = messages["unrecognized"]
+	}
+	return strings.NewReplacer("{field}", field, "{param}", param, "{rule}", rule).Replace(template)
+}
+
+// engine returns the validator used by Gin
+func engine() *validator.Validate {
+	return binding.Validator.Engine().(*validator.Validate)
+}
+
+// hasTag tells whether a field of the struct `obj` points to has the struct tag `tag`
+func hasTag(obj interface{}, tag string) bool {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup(tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldName names a field after its JSON, query, path parameter or header name
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri", "header"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
@@ -0,0 +1,203 @@
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+
+	"my-microservice/configuration"
+)
+
+// Error is an application error which renders the same way on all transports.
+// Handlers can return it directly, or wrap it with fmt.Errorf and %w.
+type Error struct {
+	// Code is a stable, machine-readable identifier of the error, such as "NOT_FOUND"
+	Code string
+	// HttpStatus is the HTTP status code the error is rendered with
+	HttpStatus int
+	// GrpcCode is the GRPC status code the error is rendered with
+	GrpcCode codes.Code
+	// Retryable tells clients whether retrying the request may succeed
+	Retryable bool
+	// Message is safe to show to clients
+	Message string
+	// Metadata holds additional public details, such as the name of a missing resource
+	Metadata map[string]string
+	// Violations lists the request fields which failed validation
+	Violations []FieldViolation
+	// Err is the internal cause of the error. It is only shown in Development mode.
+	Err error
+}
+
+// FieldViolation describes a request field which breaks a validation rule
+type FieldViolation struct {
+	Field   string `json:"field" example:"name"`
+	Rule    string `json:"rule" example:"required"`
+	Message string `json:"message" example:"name is required"`
+}
+
+// The error catalog. Use the With* methods to add details to these errors.
+// TEMPLATE: Add the errors of your domain here
+var (
+	ErrInvalidArgument    = &Error{Code: "INVALID_ARGUMENT", HttpStatus: http.StatusBadRequest, GrpcCode: codes.InvalidArgument, Message: "The request is invalid"}
+	ErrUnauthenticated    = &Error{Code: "UNAUTHENTICATED", HttpStatus: http.StatusUnauthorized, GrpcCode: codes.Unauthenticated, Message: "Authentication required"}
+	ErrPermissionDenied   = &Error{Code: "PERMISSION_DENIED", HttpStatus: http.StatusForbidden, GrpcCode: codes.PermissionDenied, Message: "Permission denied"}
+	ErrNotFound           = &Error{Code: "NOT_FOUND", HttpStatus: http.StatusNotFound, GrpcCode: codes.NotFound, Message: "The requested resource was not found"}
+	ErrNotAcceptable      = &Error{Code: "NOT_ACCEPTABLE", HttpStatus: http.StatusNotAcceptable, GrpcCode: codes.InvalidArgument, Message: "The response cannot be produced in any of the accepted media types"}
+	ErrPayloadTooLarge    = &Error{Code: "PAYLOAD_TOO_LARGE", HttpStatus: http.StatusRequestEntityTooLarge, GrpcCode: codes.ResourceExhausted, Message: "The request is too large"}
+	ErrAlreadyExists      = &Error{Code: "ALREADY_EXISTS", HttpStatus: http.StatusConflict, GrpcCode: codes.AlreadyExists, Message: "The resource already exists"}
+	ErrConflict           = &Error{Code: "CONFLICT", HttpStatus: http.StatusConflict, GrpcCode: codes.Aborted, Retryable: true, Message: "The request conflicts with a concurrent change"}
+	ErrFailedPrecondition = &Error{Code: "FAILED_PRECONDITION", HttpStatus: http.StatusConflict, GrpcCode: codes.FailedPrecondition, Message: "The resource is not in the required state"}
+	ErrResourceExhausted  = &Error{Code: "RESOURCE_EXHAUSTED", HttpStatus: http.StatusTooManyRequests, GrpcCode: codes.ResourceExhausted, Retryable: true, Message: "Too many requests, please retry later"}
+	ErrDeadlineExceeded   = &Error{Code: "DEADLINE_EXCEEDED", HttpStatus: http.StatusGatewayTimeout, GrpcCode: codes.DeadlineExceeded, Retryable: true, Message: "The request timed out"}
+	ErrCanceled           = &Error{Code: "CANCELED", HttpStatus: 499, GrpcCode: codes.Canceled, Retryable: true, Message: "The request was canceled"}
+	ErrUnavailable        = &Error{Code: "UNAVAILABLE", HttpStatus: http.StatusServiceUnavailable, GrpcCode: codes.Unavailable, Retryable: true, Message: "The service is unavailable, please retry later"}
+	ErrUnimplemented      = &Error{Code: "UNIMPLEMENTED", HttpStatus: http.StatusNotImplemented, GrpcCode: codes.Unimplemented, Message: "The operation is not implemented"}
+	ErrInternal           = &Error{Code: "INTERNAL", HttpStatus: http.StatusInternalServerError, GrpcCode: codes.Internal, Message: "An internal error has occurred"}
+)
+
+// catalog lists the errors Lookup can find
+// TEMPLATE: List the errors of your domain here as well
+var catalog = []*Error{
+	ErrInvalidArgument, ErrUnauthenticated, ErrPermissionDenied, ErrNotFound, ErrNotAcceptable, ErrPayloadTooLarge,
+	ErrAlreadyExists, ErrConflict, ErrFailedPrecondition, ErrResourceExhausted, ErrDeadlineExceeded,
+	ErrCanceled, ErrUnavailable, ErrUnimplemented, ErrInternal,
+}
+
+// Lookup returns the catalog error with the given code, or ErrInternal if there
+// is none. It restores errors which were stored by their code only, such as the
+// errors of failed jobs.
+func Lookup(code string) *Error {
+	for _, e := range catalog {
+		if e.Code == code {
+			return e
+		}
+	}
+	return ErrInternal
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors with the same Code, so that errors.Is(err, ErrNotFound)
+// holds for errors derived from ErrNotFound.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns a copy of the error caused by `err`
+func (e *Error) Wrap(err error) *Error {
+	c := e.clone()
+	c.Err = err
+	return c
+}
+
+// WithMessage returns a copy of the error with another public message
+func (e *Error) WithMessage(message string) *Error {
+	c := e.clone()
+	c.Message = message
+	return c
+}
+
+// WithMetadata returns a copy of the error with an additional public detail
+func (e *Error) WithMetadata(key, value string) *Error {
+	c := e.clone()
+	c.Metadata = make(map[string]string, len(e.Metadata)+1)
+	for k, v := range e.Metadata {
+		c.Metadata[k] = v
+	}
+	c.Metadata[key] = value
+	return c
+}
+
+// WithViolations returns a copy of the error with additional field violations
+func (e *Error) WithViolations(violations ...FieldViolation) *Error {
+	c := e.clone()
+	c.Violations = append(append([]FieldViolation(nil), e.Violations...), violations...)
+	return c
+}
+
+func (e *Error) clone() *Error {
+	c := *e
+	return &c
+}
+
+// GRPCStatus renders the error as a GRPC status with an ErrorInfo detail. It is
+// used by the GRPC library when a handler returns the error directly. The
+// Errors interceptors add the request details as well.
+func (e *Error) GRPCStatus() *status.Status {
+	return e.Status("")
+}
+
+// Status renders the error as a GRPC status with an ErrorInfo detail, a
+// BadRequest detail if there are field violations and, if `correlationId` is
+// set, a RequestInfo detail. In Development mode, the
+// internal cause is added as a DebugInfo detail.
+func (e *Error) Status(correlationId string) *status.Status {
+	metadata := map[string]string{"retryable": strconv.FormatBool(e.Retryable)}
+	for k, v := range e.Metadata {
+		metadata[k] = v
+	}
+	details := []protoiface.MessageV1{&errdetails.ErrorInfo{
+		Reason:   e.Code,
+		Domain:   configuration.ErrorDomain,
+		Metadata: metadata,
+	}}
+	if len(e.Violations) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, violation := range e.Violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       violation.Field,
+				Description: violation.Message,
+			})
+		}
+		details = append(details, badRequest)
+	}
+	if correlationId != "" {
+		details = append(details, &errdetails.RequestInfo{RequestId: correlationId})
+	}
+	if e.Err != nil && configuration.AppConfig().Development {
+		details = append(details, &errdetails.DebugInfo{Detail: e.Err.Error()})
+	}
+
+	st := status.New(e.GrpcCode, e.Message)
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// From converts any error into an Error. Context errors are mapped to
+// ErrDeadlineExceeded and ErrCanceled, everything else which is not an Error
+// becomes an ErrInternal, hiding the original message from clients.
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrDeadlineExceeded.Wrap(err)
+	case errors.Is(err, context.Canceled):
+		return ErrCanceled.Wrap(err)
+	}
+	return ErrInternal.Wrap(err)
+}
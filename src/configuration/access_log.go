@@ -0,0 +1,113 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/coderollers/go-utils"
+	"google.golang.org/grpc/codes"
+)
+
+// CAccessLog holds the settings of the structured access log
+type CAccessLog struct {
+	// Enabled activates the access log. Defaults to true.
+	Enabled bool
+	// SkipPaths is a list of HTTP paths which are never logged, such as health
+	// checks. Defaults to "/healthz,/readyz,/metrics".
+	SkipPaths []string
+	// SamplePercent is the percentage of requests which are logged, keyed by route
+	// ("GET /v1/"), status code ("404") or status class ("2xx"), looked up in this
+	// order. Requests matching no key, failing with a server error or exceeding
+	// the slow request threshold are always logged.
+	SamplePercent map[string]int32
+	// SlowRequestThresholdMs logs requests taking longer than this as warnings.
+	// Set to 0 to disable. Defaults to 1000.
+	SlowRequestThresholdMs int32
+	// LogHeaders adds the request headers to the access log. Defaults to false.
+	LogHeaders bool
+	// RedactHeaders is a list of headers whose values are replaced when logged.
+	// Defaults to "Authorization,Cookie,Set-Cookie,X-Api-Key".
+	RedactHeaders []string
+	// GrpcSkipMethods is a list of GRPC full method name prefixes which are never
+	// logged. Defaults to "/grpc.health.v1.Health/".
+	GrpcSkipMethods []string
+	// GrpcLevels overrides the log level of GRPC calls per status code, such as
+	// "NotFound=warn". Codes which are not listed use the default levels.
+	GrpcLevels map[string]string
+	// GrpcLogPayloads adds the request and response messages of unary GRPC calls
+	// to the access log. Only honored in Development mode. Defaults to false.
+	GrpcLogPayloads bool
+	// RedactFields is a list of message field names, in snake_case or
+	// lowerCamelCase, whose values are replaced when payloads are logged.
+	// Defaults to "password,token,secret,authorization".
+	RedactFields []string
+}
+
+func (c *Configuration) loadAccessLogConf() {
+	c.AccessLog.Enabled = utils.EnvOrDefaultBool("ACCESS_LOG_ENABLED", true)
+	c.AccessLog.SkipPaths = utils.EnvOrDefaultStringSlice("ACCESS_LOG_SKIP_PATHS", ",", []string{"/healthz", "/readyz", "/metrics"})
+	c.AccessLog.SlowRequestThresholdMs = utils.EnvOrDefaultInt32("ACCESS_LOG_SLOW_THRESHOLD_MS", 1000)
+	c.AccessLog.LogHeaders = utils.EnvOrDefaultBool("ACCESS_LOG_HEADERS", false)
+	c.AccessLog.RedactHeaders = utils.EnvOrDefaultStringSlice("ACCESS_LOG_REDACT_HEADERS", ",", []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key"})
+	c.AccessLog.GrpcSkipMethods = utils.EnvOrDefaultStringSlice("ACCESS_LOG_GRPC_SKIP_METHODS", ",", []string{"/grpc.health.v1.Health/"})
+	c.AccessLog.GrpcLogPayloads = utils.EnvOrDefaultBool("ACCESS_LOG_GRPC_PAYLOADS", false)
+	c.AccessLog.RedactFields = utils.EnvOrDefaultStringSlice("ACCESS_LOG_REDACT_FIELDS", ",", []string{"password", "token", "secret", "authorization"})
+	c.AccessLog.SamplePercent = make(map[string]int32)
+	c.AccessLog.GrpcLevels = make(map[string]string)
+
+	// ACCESS_LOG_SAMPLE_PERCENT has the format "2xx=10,404=50,GET /v1/=100"
+	for _, entry := range utils.EnvOrDefaultStringSlice("ACCESS_LOG_SAMPLE_PERCENT", ",", nil) {
+		idx := strings.LastIndex(entry, "=")
+		if idx < 0 {
+			log.Fatalf("invalid ACCESS_LOG_SAMPLE_PERCENT entry %q, expected <route or status>=<percent>", entry)
+		}
+		percent, err := strconv.ParseInt(strings.TrimSpace(entry[idx+1:]), 10, 32)
+		if err != nil {
+			log.Fatalf("invalid ACCESS_LOG_SAMPLE_PERCENT entry %q: %s", entry, err.Error())
+		}
+		c.AccessLog.SamplePercent[strings.TrimSpace(entry[:idx])] = int32(percent)
+	}
+
+	// ACCESS_LOG_GRPC_LEVELS has the format "NotFound=warn,Canceled=debug"
+	for _, entry := range utils.EnvOrDefaultStringSlice("ACCESS_LOG_GRPC_LEVELS", ",", nil) {
+		idx := strings.LastIndex(entry, "=")
+		if idx < 0 {
+			log.Fatalf("invalid ACCESS_LOG_GRPC_LEVELS entry %q, expected <status code>=<level>", entry)
+		}
+		c.AccessLog.GrpcLevels[strings.TrimSpace(entry[:idx])] = strings.ToLower(strings.TrimSpace(entry[idx+1:]))
+	}
+}
+
+func (c *CAccessLog) validate() error {
+	var errs []error
+	validCodes := make(map[string]bool)
+	for code := codes.OK; code <= codes.Unauthenticated; code++ {
+		validCodes[code.String()] = true
+	}
+	for code, level := range c.GrpcLevels {
+		if !validCodes[code] {
+			errs = append(errs, fmt.Errorf("ACCESS_LOG_GRPC_LEVELS has an unknown status code %q", code))
+		}
+		switch level {
+		case "debug", "info", "warn", "error":
+		default:
+			errs = append(errs, fmt.Errorf("ACCESS_LOG_GRPC_LEVELS has an invalid level %q for %s, expected debug, info, warn or error", level, code))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SamplePercentFor returns the percentage of requests to `route` answered with
+// `status` which are logged
+func (c *CAccessLog) SamplePercentFor(route string, status int) int32 {
+	code := strconv.Itoa(status)
+	for _, key := range []string{route, code, code[:1] + "xx"} {
+		if percent, ok := c.SamplePercent[key]; ok {
+			return percent
+		}
+	}
+	return 100
+}
@@ -5,7 +5,17 @@ import (
 )
 
 type Configuration struct {
-	Swagger CSwagger
+	Swagger     CSwagger
+	Limiter     CLimiter
+	Timeouts    CTimeouts
+	HttpServer  CHttpServer
+	Grpc        CGrpc
+	Management  CManagement
+	Maintenance CMaintenance
+	AccessLog   CAccessLog
+	Errors      CErrors
+	Jobs        CJobs
+	Pagination  CPagination
 
 	// Dependencies section
 
@@ -20,6 +30,11 @@ type Configuration struct {
 	// end before forcibly exiting when it receives a termination signal from the
 	// orchestrator or OS.
 	CleanupTimeoutSec int32
+	// DrainPropagationDelaySec sets how long the microservice keeps serving after
+	// it has been marked not-ready on a termination signal, giving the orchestrator
+	// time to stop routing traffic to it. The pod termination grace period must be
+	// longer than this plus CleanupTimeoutSec.
+	DrainPropagationDelaySec int32
 	// Environment is a string representing the environment where the microservice is
 	// deployed, such as "staging" or "production". Optional.
 	Environment string
@@ -75,6 +90,17 @@ func loadEnvironmentVariables() {
 	appConfig.JaegerEndpoint = utils.EnvOrDefault("JAEGER_ENDPOINT", "")
 	appConfig.IngressPrefix = utils.EnvOrDefault("INGRESS_PREFIX", "")
 	appConfig.CleanupTimeoutSec = utils.EnvOrDefaultInt32("SHUTDOWN_TIMEOUT", 300)
+	appConfig.DrainPropagationDelaySec = utils.EnvOrDefaultInt32("DRAIN_PROPAGATION_DELAY", 5)
 	appConfig.HttpPort = utils.EnvOrDefaultInt32("HTTP_PORT", 8080)
 	appConfig.GrpcPort = utils.EnvOrDefaultInt32("GRPC_PORT", 9000)
+	appConfig.loadLimiterConf()
+	appConfig.loadTimeoutsConf()
+	appConfig.loadHttpServerConf()
+	appConfig.loadGrpcConf()
+	appConfig.loadManagementConf()
+	appConfig.loadMaintenanceConf()
+	appConfig.loadAccessLogConf()
+	appConfig.loadErrorsConf()
+	appConfig.loadJobsConf()
+	appConfig.loadPaginationConf()
 }
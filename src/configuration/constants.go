@@ -3,6 +3,7 @@ package configuration
 // Server related constants
 const (
 	CorrelationIdKey = "correlation_id"
+	ErrorDomain      = "my-microservice" // TEMPLATE: Change this to the domain reported in GRPC error details
 	// TEMPLATE: Add here more service related constants
 )
 
@@ -12,3 +13,8 @@ const (
 	OTVersion = "1.0"
 	OTSchema  = "/v1"
 )
+
+// Metrics related constants
+const (
+	MetricsNamespace = "my_microservice" // TEMPLATE: Change this to reflect your microservice name within Prometheus metrics
+)
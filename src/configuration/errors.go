@@ -0,0 +1,40 @@
+package configuration
+
+import (
+	"fmt"
+
+	"github.com/coderollers/go-utils"
+)
+
+// Formats of the HTTP failure responses
+const (
+	ErrorFormatEnvelope = "envelope"
+	ErrorFormatProblem  = "problem"
+)
+
+// CErrors holds the settings of the HTTP failure responses
+type CErrors struct {
+	// Format is the default shape of the failure responses: "envelope" for the
+	// JSONFailureResult envelope or "problem" for RFC 9457 problem details.
+	// Clients can always ask for either one through the Accept header.
+	// Defaults to "envelope".
+	Format string
+	// TypeBaseUri is the prefix of the problem type URIs, which are completed
+	// with the error code, such as "https://errors.example.com/not-found". If
+	// empty, the type of all problems is "about:blank".
+	TypeBaseUri string
+}
+
+func (c *Configuration) loadErrorsConf() {
+	c.Errors.Format = utils.EnvOrDefault("ERROR_FORMAT", ErrorFormatEnvelope)
+	c.Errors.TypeBaseUri = utils.EnvOrDefault("ERROR_TYPE_BASE_URI", "")
+}
+
+func (c *CErrors) validate() error {
+	switch c.Format {
+	case ErrorFormatEnvelope, ErrorFormatProblem:
+		return nil
+	default:
+		return fmt.Errorf("ERROR_FORMAT must be %q or %q, got %q", ErrorFormatEnvelope, ErrorFormatProblem, c.Format)
+	}
+}
@@ -0,0 +1,96 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/coderollers/go-utils"
+)
+
+// CGrpc holds the tuning options of the GRPC server. Message size limits apply
+// in both native and multiplexed mode, while the transport options (streams,
+// keepalive, connection timeout and buffers) only apply to the native listener.
+// In multiplexed mode the HTTP server hardening profile is used instead.
+type CGrpc struct {
+	// MaxRecvMsgBytes is the largest message the server accepts. Defaults to 4 MiB.
+	MaxRecvMsgBytes int32
+	// MaxSendMsgBytes is the largest message the server sends. Defaults to 4 MiB.
+	MaxSendMsgBytes int32
+	// MaxConcurrentStreams is the maximum number of concurrent streams per client
+	// connection. Defaults to 250.
+	MaxConcurrentStreams int32
+	// KeepaliveTimeSec is how long a connection may be inactive before the server
+	// pings the client. Defaults to 120.
+	KeepaliveTimeSec int32
+	// KeepaliveTimeoutSec is how long the server waits for a ping reply before
+	// closing the connection. Defaults to 20.
+	KeepaliveTimeoutSec int32
+	// MaxConnectionIdleSec closes connections which have had no active RPCs for
+	// this long. Set to 0 to disable. Defaults to 300.
+	MaxConnectionIdleSec int32
+	// MaxConnectionAgeSec closes connections older than this, so that clients
+	// reconnect and rebalance across pods after a scale-out. Set to 0 to disable.
+	// Defaults to 1800.
+	MaxConnectionAgeSec int32
+	// MaxConnectionAgeGraceSec is the time in-flight RPCs are given to finish once
+	// a connection has reached its maximum age. Defaults to 60.
+	MaxConnectionAgeGraceSec int32
+	// KeepaliveMinTimeSec is the minimum interval clients may send keepalive pings
+	// at. Clients pinging more often are disconnected. Defaults to 30.
+	KeepaliveMinTimeSec int32
+	// KeepalivePermitWithoutStream allows clients to send keepalive pings while
+	// they have no active streams. Defaults to false.
+	KeepalivePermitWithoutStream bool
+	// ConnectionTimeoutSec is the time allowed for new connections to complete the
+	// handshake. Defaults to 120.
+	ConnectionTimeoutSec int32
+	// WriteBufferBytes is the size of the per-connection write buffer. Defaults to 32 KiB.
+	WriteBufferBytes int32
+	// ReadBufferBytes is the size of the per-connection read buffer. Defaults to 32 KiB.
+	ReadBufferBytes int32
+}
+
+func (c *Configuration) loadGrpcConf() {
+	c.Grpc.MaxRecvMsgBytes = utils.EnvOrDefaultInt32("GRPC_MAX_RECV_MSG_BYTES", 4<<20)
+	c.Grpc.MaxSendMsgBytes = utils.EnvOrDefaultInt32("GRPC_MAX_SEND_MSG_BYTES", 4<<20)
+	c.Grpc.MaxConcurrentStreams = utils.EnvOrDefaultInt32("GRPC_MAX_CONCURRENT_STREAMS", 250)
+	c.Grpc.KeepaliveTimeSec = utils.EnvOrDefaultInt32("GRPC_KEEPALIVE_TIME_SEC", 120)
+	c.Grpc.KeepaliveTimeoutSec = utils.EnvOrDefaultInt32("GRPC_KEEPALIVE_TIMEOUT_SEC", 20)
+	c.Grpc.MaxConnectionIdleSec = utils.EnvOrDefaultInt32("GRPC_MAX_CONNECTION_IDLE_SEC", 300)
+	c.Grpc.MaxConnectionAgeSec = utils.EnvOrDefaultInt32("GRPC_MAX_CONNECTION_AGE_SEC", 1800)
+	c.Grpc.MaxConnectionAgeGraceSec = utils.EnvOrDefaultInt32("GRPC_MAX_CONNECTION_AGE_GRACE_SEC", 60)
+	c.Grpc.KeepaliveMinTimeSec = utils.EnvOrDefaultInt32("GRPC_KEEPALIVE_MIN_TIME_SEC", 30)
+	c.Grpc.KeepalivePermitWithoutStream = utils.EnvOrDefaultBool("GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM", false)
+	c.Grpc.ConnectionTimeoutSec = utils.EnvOrDefaultInt32("GRPC_CONNECTION_TIMEOUT_SEC", 120)
+	c.Grpc.WriteBufferBytes = utils.EnvOrDefaultInt32("GRPC_WRITE_BUFFER_BYTES", 32<<10)
+	c.Grpc.ReadBufferBytes = utils.EnvOrDefaultInt32("GRPC_READ_BUFFER_BYTES", 32<<10)
+}
+
+func (c *CGrpc) validate() error {
+	var errs []error
+	for _, setting := range []struct {
+		name      string
+		value     int32
+		allowZero bool
+	}{
+		{"GRPC_MAX_RECV_MSG_BYTES", c.MaxRecvMsgBytes, false},
+		{"GRPC_MAX_SEND_MSG_BYTES", c.MaxSendMsgBytes, false},
+		{"GRPC_MAX_CONCURRENT_STREAMS", c.MaxConcurrentStreams, false},
+		{"GRPC_KEEPALIVE_TIME_SEC", c.KeepaliveTimeSec, false},
+		{"GRPC_KEEPALIVE_TIMEOUT_SEC", c.KeepaliveTimeoutSec, false},
+		{"GRPC_CONNECTION_TIMEOUT_SEC", c.ConnectionTimeoutSec, false},
+		{"GRPC_MAX_CONNECTION_IDLE_SEC", c.MaxConnectionIdleSec, true},
+		{"GRPC_MAX_CONNECTION_AGE_SEC", c.MaxConnectionAgeSec, true},
+		{"GRPC_MAX_CONNECTION_AGE_GRACE_SEC", c.MaxConnectionAgeGraceSec, true},
+		{"GRPC_KEEPALIVE_MIN_TIME_SEC", c.KeepaliveMinTimeSec, true},
+		{"GRPC_WRITE_BUFFER_BYTES", c.WriteBufferBytes, true},
+		{"GRPC_READ_BUFFER_BYTES", c.ReadBufferBytes, true},
+	} {
+		if setting.allowZero && setting.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %d", setting.name, setting.value))
+		} else if !setting.allowZero && setting.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %d", setting.name, setting.value))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,125 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/coderollers/go-utils"
+)
+
+// CHttpServer holds the hardening profile of the HTTP listener. The defaults are
+// safe for production use and protect against slow clients as well as oversized
+// headers and bodies.
+type CHttpServer struct {
+	// ReadHeaderTimeoutSec is the time allowed to read the request headers. Defaults to 10.
+	ReadHeaderTimeoutSec int32
+	// ReadTimeoutSec is the time allowed to read the entire request, including the
+	// body. Defaults to 60.
+	ReadTimeoutSec int32
+	// WriteTimeoutSec is the time allowed from the end of the request headers until
+	// the response has been written. It must be longer than the request timeouts.
+	// In multiplexed mode this also bounds the lifetime of native GRPC streams, so
+	// set it to 0 if you serve long-lived streams on the HTTP port, a warning is
+	// logged at startup otherwise. Defaults to 60.
+	WriteTimeoutSec int32
+	// IdleTimeoutSec is how long keep-alive connections may stay idle. Defaults to 120.
+	IdleTimeoutSec int32
+	// MaxHeaderBytes is the maximum size of the request headers. Defaults to 64 KiB.
+	MaxHeaderBytes int32
+	// MaxBodyBytes is the maximum size of request bodies accepted by Gin routes.
+	// Set to 0 to disable. Defaults to 4 MiB.
+	MaxBodyBytes int64
+	// RouteMaxBodyBytes overrides MaxBodyBytes per route, keyed by method and route
+	// template, such as "POST /v1/upload".
+	RouteMaxBodyBytes map[string]int64
+	// MaxConnections is the maximum number of simultaneously open connections. Set
+	// to 0 to disable. Defaults to 4096.
+	MaxConnections int32
+	// Http2MaxConcurrentStreams is the maximum number of concurrent HTTP/2 streams
+	// per connection. Defaults to 250.
+	Http2MaxConcurrentStreams int32
+	// Http2MaxReadFrameSize is the largest HTTP/2 frame the server accepts. Must be
+	// between 16 KiB and 16 MiB. Defaults to 1 MiB.
+	Http2MaxReadFrameSize int32
+	// Http2MaxUploadBufferPerConnection is the HTTP/2 flow control window of a
+	// connection. Defaults to 1 MiB.
+	Http2MaxUploadBufferPerConnection int32
+	// Http2MaxUploadBufferPerStream is the HTTP/2 flow control window of a single
+	// stream. Defaults to 256 KiB.
+	Http2MaxUploadBufferPerStream int32
+}
+
+func (c *Configuration) loadHttpServerConf() {
+	c.HttpServer.ReadHeaderTimeoutSec = utils.EnvOrDefaultInt32("HTTP_READ_HEADER_TIMEOUT_SEC", 10)
+	c.HttpServer.ReadTimeoutSec = utils.EnvOrDefaultInt32("HTTP_READ_TIMEOUT_SEC", 60)
+	c.HttpServer.WriteTimeoutSec = utils.EnvOrDefaultInt32("HTTP_WRITE_TIMEOUT_SEC", 60)
+	c.HttpServer.IdleTimeoutSec = utils.EnvOrDefaultInt32("HTTP_IDLE_TIMEOUT_SEC", 120)
+	c.HttpServer.MaxHeaderBytes = utils.EnvOrDefaultInt32("HTTP_MAX_HEADER_BYTES", 64<<10)
+	c.HttpServer.MaxBodyBytes = utils.EnvOrDefaultInt64("HTTP_MAX_BODY_BYTES", 4<<20)
+	c.HttpServer.MaxConnections = utils.EnvOrDefaultInt32("HTTP_MAX_CONNECTIONS", 4096)
+	c.HttpServer.Http2MaxConcurrentStreams = utils.EnvOrDefaultInt32("HTTP2_MAX_CONCURRENT_STREAMS", 250)
+	c.HttpServer.Http2MaxReadFrameSize = utils.EnvOrDefaultInt32("HTTP2_MAX_READ_FRAME_SIZE", 1<<20)
+	c.HttpServer.Http2MaxUploadBufferPerConnection = utils.EnvOrDefaultInt32("HTTP2_MAX_UPLOAD_BUFFER_PER_CONNECTION", 1<<20)
+	c.HttpServer.Http2MaxUploadBufferPerStream = utils.EnvOrDefaultInt32("HTTP2_MAX_UPLOAD_BUFFER_PER_STREAM", 256<<10)
+	c.HttpServer.RouteMaxBodyBytes = make(map[string]int64)
+
+	// ROUTE_MAX_BODY_BYTES has the format "POST /v1/upload=104857600,PUT /v1/item=1024"
+	for _, entry := range utils.EnvOrDefaultStringSlice("ROUTE_MAX_BODY_BYTES", ",", nil) {
+		idx := strings.LastIndex(entry, "=")
+		if idx < 0 {
+			log.Fatalf("invalid ROUTE_MAX_BODY_BYTES entry %q, expected <route>=<bytes>", entry)
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(entry[idx+1:]), 10, 64)
+		if err != nil {
+			log.Fatalf("invalid ROUTE_MAX_BODY_BYTES entry %q: %s", entry, err.Error())
+		}
+		c.HttpServer.RouteMaxBodyBytes[strings.TrimSpace(entry[:idx])] = size
+	}
+}
+
+// MaxBodyBytesFor returns the maximum body size configured for `route`, falling
+// back to MaxBodyBytes.
+func (c *CHttpServer) MaxBodyBytesFor(route string) int64 {
+	if size, ok := c.RouteMaxBodyBytes[route]; ok {
+		return size
+	}
+	return c.MaxBodyBytes
+}
+
+func (c *CHttpServer) validate() error {
+	var errs []error
+	for _, setting := range []struct {
+		name      string
+		value     int64
+		allowZero bool
+	}{
+		{"HTTP_READ_HEADER_TIMEOUT_SEC", int64(c.ReadHeaderTimeoutSec), true},
+		{"HTTP_READ_TIMEOUT_SEC", int64(c.ReadTimeoutSec), true},
+		{"HTTP_WRITE_TIMEOUT_SEC", int64(c.WriteTimeoutSec), true},
+		{"HTTP_IDLE_TIMEOUT_SEC", int64(c.IdleTimeoutSec), true},
+		{"HTTP_MAX_HEADER_BYTES", int64(c.MaxHeaderBytes), false},
+		{"HTTP_MAX_BODY_BYTES", c.MaxBodyBytes, true},
+		{"HTTP_MAX_CONNECTIONS", int64(c.MaxConnections), true},
+		{"HTTP2_MAX_CONCURRENT_STREAMS", int64(c.Http2MaxConcurrentStreams), false},
+		{"HTTP2_MAX_UPLOAD_BUFFER_PER_CONNECTION", int64(c.Http2MaxUploadBufferPerConnection), false},
+		{"HTTP2_MAX_UPLOAD_BUFFER_PER_STREAM", int64(c.Http2MaxUploadBufferPerStream), false},
+	} {
+		if setting.allowZero && setting.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %d", setting.name, setting.value))
+		} else if !setting.allowZero && setting.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %d", setting.name, setting.value))
+		}
+	}
+	for route, size := range c.RouteMaxBodyBytes {
+		if size < 0 {
+			errs = append(errs, fmt.Errorf("ROUTE_MAX_BODY_BYTES of %q must not be negative, got %d", route, size))
+		}
+	}
+	if c.Http2MaxReadFrameSize < 16<<10 || c.Http2MaxReadFrameSize > 16<<20 {
+		errs = append(errs, fmt.Errorf("HTTP2_MAX_READ_FRAME_SIZE must be between 16 KiB and 16 MiB, got %d", c.Http2MaxReadFrameSize))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,47 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/coderollers/go-utils"
+)
+
+// CJobs holds the settings of the asynchronous job subsystem
+type CJobs struct {
+	// Workers is the number of jobs run concurrently. Defaults to 4.
+	Workers int32
+	// QueueSize is the number of jobs which can wait for a worker. Jobs
+	// submitted while the queue is full are rejected. Defaults to 100.
+	QueueSize int32
+	// RetentionSec is how long finished jobs are kept by the in-memory store.
+	// Defaults to 3600.
+	RetentionSec int32
+	// StopTimeoutSec is how long the running jobs are given to finish at
+	// shutdown before being cancelled. Defaults to 30.
+	StopTimeoutSec int32
+}
+
+func (c *Configuration) loadJobsConf() {
+	c.Jobs.Workers = utils.EnvOrDefaultInt32("JOBS_WORKERS", 4)
+	c.Jobs.QueueSize = utils.EnvOrDefaultInt32("JOBS_QUEUE_SIZE", 100)
+	c.Jobs.RetentionSec = utils.EnvOrDefaultInt32("JOBS_RETENTION_SEC", 3600)
+	c.Jobs.StopTimeoutSec = utils.EnvOrDefaultInt32("JOBS_STOP_TIMEOUT_SEC", 30)
+}
+
+func (c *CJobs) validate() error {
+	var errs []error
+	if c.Workers < 1 {
+		errs = append(errs, fmt.Errorf("JOBS_WORKERS must be at least 1, got %d", c.Workers))
+	}
+	if c.QueueSize < 1 {
+		errs = append(errs, fmt.Errorf("JOBS_QUEUE_SIZE must be at least 1, got %d", c.QueueSize))
+	}
+	if c.RetentionSec < 1 {
+		errs = append(errs, fmt.Errorf("JOBS_RETENTION_SEC must be at least 1, got %d", c.RetentionSec))
+	}
+	if c.StopTimeoutSec < 0 {
+		errs = append(errs, fmt.Errorf("JOBS_STOP_TIMEOUT_SEC must not be negative, got %d", c.StopTimeoutSec))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,77 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/coderollers/go-utils"
+)
+
+// CLimiter holds the settings of the adaptive concurrency limiters placed in
+// front of the Gin router and the GRPC server.
+type CLimiter struct {
+	// Enabled activates the concurrency limiters. Defaults to true.
+	Enabled bool
+	// InitialLimit is the number of concurrent requests allowed before any latency
+	// has been observed. Must be between MinLimit and MaxLimit. Defaults to 100.
+	InitialLimit int32
+	// MinLimit is the lowest the limit can be reduced to. Must be at least 1.
+	// Defaults to 10.
+	MinLimit int32
+	// MaxLimit is the highest the limit can grow to. Defaults to 1000.
+	MaxLimit int32
+	// BackoffPercent is the percentage the limit is multiplied with whenever
+	// congestion is detected, between 1 and 99. Defaults to 90.
+	BackoffPercent int32
+	// LatencyTolerancePercent is how much slower than the long-term average
+	// latency a request may be before it is considered a sign of congestion.
+	// Must be above 100. Defaults to 200, meaning twice the average.
+	LatencyTolerancePercent int32
+	// LatencyThresholdMs is an absolute latency above which a request is always
+	// considered a sign of congestion. Must be positive. Defaults to 5000.
+	LatencyThresholdMs int32
+	// CriticalPaths is a list of HTTP path prefixes which are never shed, such as
+	// the health checks served when the management listener is disabled.
+	CriticalPaths []string
+	// CriticalMethods is a list of GRPC full method name prefixes which are never
+	// shed.
+	CriticalMethods []string
+}
+
+func (c *Configuration) loadLimiterConf() {
+	c.Limiter.Enabled = utils.EnvOrDefaultBool("LIMITER_ENABLED", true)
+	c.Limiter.InitialLimit = utils.EnvOrDefaultInt32("LIMITER_INITIAL_LIMIT", 100)
+	c.Limiter.MinLimit = utils.EnvOrDefaultInt32("LIMITER_MIN_LIMIT", 10)
+	c.Limiter.MaxLimit = utils.EnvOrDefaultInt32("LIMITER_MAX_LIMIT", 1000)
+	c.Limiter.BackoffPercent = utils.EnvOrDefaultInt32("LIMITER_BACKOFF_PERCENT", 90)
+	c.Limiter.LatencyTolerancePercent = utils.EnvOrDefaultInt32("LIMITER_LATENCY_TOLERANCE_PERCENT", 200)
+	c.Limiter.LatencyThresholdMs = utils.EnvOrDefaultInt32("LIMITER_LATENCY_THRESHOLD_MS", 5000)
+	c.Limiter.CriticalPaths = utils.EnvOrDefaultStringSlice("LIMITER_CRITICAL_PATHS", ",", []string{"/healthz", "/readyz"})
+	c.Limiter.CriticalMethods = utils.EnvOrDefaultStringSlice("LIMITER_CRITICAL_METHODS", ",", []string{"/grpc.health.v1.Health/"})
+}
+
+func (c *CLimiter) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	var errs []error
+	if c.MinLimit < 1 {
+		errs = append(errs, fmt.Errorf("LIMITER_MIN_LIMIT must be at least 1, got %d", c.MinLimit))
+	}
+	if c.MaxLimit < c.MinLimit {
+		errs = append(errs, fmt.Errorf("LIMITER_MAX_LIMIT (%d) must not be lower than LIMITER_MIN_LIMIT (%d)", c.MaxLimit, c.MinLimit))
+	}
+	if c.InitialLimit < c.MinLimit || c.InitialLimit > c.MaxLimit {
+		errs = append(errs, fmt.Errorf("LIMITER_INITIAL_LIMIT must be between LIMITER_MIN_LIMIT (%d) and LIMITER_MAX_LIMIT (%d), got %d", c.MinLimit, c.MaxLimit, c.InitialLimit))
+	}
+	if c.BackoffPercent < 1 || c.BackoffPercent > 99 {
+		errs = append(errs, fmt.Errorf("LIMITER_BACKOFF_PERCENT must be between 1 and 99, got %d", c.BackoffPercent))
+	}
+	if c.LatencyTolerancePercent <= 100 {
+		errs = append(errs, fmt.Errorf("LIMITER_LATENCY_TOLERANCE_PERCENT must be above 100, got %d", c.LatencyTolerancePercent))
+	}
+	if c.LatencyThresholdMs <= 0 {
+		errs = append(errs, fmt.Errorf("LIMITER_LATENCY_THRESHOLD_MS must be positive, got %d", c.LatencyThresholdMs))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,38 @@
+package configuration
+
+import (
+	"fmt"
+
+	"github.com/coderollers/go-utils"
+)
+
+// CMaintenance holds the settings of the maintenance mode, in which the
+// microservice reports not-ready and rejects requests while staying alive. The
+// mode can be toggled at runtime through the management endpoints.
+type CMaintenance struct {
+	// Enabled starts the microservice in maintenance mode. Defaults to false.
+	Enabled bool
+	// RetryAfterSec is the Retry-After value sent with rejected HTTP requests.
+	// Defaults to 30.
+	RetryAfterSec int32
+	// AllowedPaths is a list of HTTP path prefixes which are served in maintenance
+	// mode, such as health checks.
+	AllowedPaths []string
+	// AllowedMethods is a list of GRPC full method name prefixes which are served
+	// in maintenance mode.
+	AllowedMethods []string
+}
+
+func (c *Configuration) loadMaintenanceConf() {
+	c.Maintenance.Enabled = utils.EnvOrDefaultBool("MAINTENANCE_MODE", false)
+	c.Maintenance.RetryAfterSec = utils.EnvOrDefaultInt32("MAINTENANCE_RETRY_AFTER_SEC", 30)
+	c.Maintenance.AllowedPaths = utils.EnvOrDefaultStringSlice("MAINTENANCE_ALLOWED_PATHS", ",", []string{"/healthz", "/readyz"})
+	c.Maintenance.AllowedMethods = utils.EnvOrDefaultStringSlice("MAINTENANCE_ALLOWED_METHODS", ",", []string{"/grpc.health.v1.Health/"})
+}
+
+func (c *CMaintenance) validate() error {
+	if c.RetryAfterSec < 0 {
+		return fmt.Errorf("MAINTENANCE_RETRY_AFTER_SEC must not be negative, got %d", c.RetryAfterSec)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/coderollers/go-utils"
+)
+
+// CManagement holds the settings of the management listener, which serves the
+// operational endpoints (probes, metrics, pprof, configuration, tasks and log
+// level) away from the public router.
+type CManagement struct {
+	// Port is the TCP port of the management listener. Set to 0 to disable it.
+	// Defaults to 8081.
+	Port int32
+	// BindAddress restricts the management listener to an interface. Set to
+	// "0.0.0.0" to listen on all interfaces, which requires a Token outside of
+	// Development mode. Defaults to "127.0.0.1".
+	BindAddress string
+	// Token, if set, must be sent as a bearer token to access the management
+	// endpoints. The health probes are always accessible.
+	Token string `json:"-"`
+}
+
+func (c *Configuration) loadManagementConf() {
+	c.Management.Port = utils.EnvOrDefaultInt32("MANAGEMENT_PORT", 8081)
+	c.Management.BindAddress = utils.EnvOrDefault("MANAGEMENT_BIND_ADDRESS", "127.0.0.1")
+	c.Management.Token = utils.EnvOrDefault("MANAGEMENT_TOKEN", "")
+}
+
+func (c *Configuration) validateManagement() error {
+	if c.Management.Port == 0 {
+		return nil
+	}
+	if c.Management.Port < 0 || c.Management.Port > 65535 {
+		return fmt.Errorf("MANAGEMENT_PORT must be between 0 and 65535, got %d", c.Management.Port)
+	}
+	if c.Management.Port == c.HttpPort || c.Management.Port == c.GrpcPort {
+		return errors.New("MANAGEMENT_PORT must differ from the HTTP and GRPC ports")
+	}
+	return nil
+}
+
+// Loopback tells whether the management listener is only reachable from the
+// host, or the pod, it runs on
+func (c CManagement) Loopback() bool {
+	if c.BindAddress == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(c.BindAddress)
+	return ip != nil && ip.IsLoopback()
+}
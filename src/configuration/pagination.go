@@ -0,0 +1,41 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/coderollers/go-utils"
+)
+
+// CPagination holds the settings of the list endpoints
+type CPagination struct {
+	// DefaultPageSize is the page size used when the client does not ask for
+	// one. Defaults to 50.
+	DefaultPageSize int32
+	// MaxPageSize caps the page size asked by clients. Defaults to 1000.
+	MaxPageSize int32
+	// TokenKey signs the page tokens, so that clients cannot forge them. It must
+	// be the same on all replicas. If not set, a random key is used, and the page
+	// tokens are only valid on the replica which issued them until it restarts.
+	TokenKey string `json:"-"`
+}
+
+func (c *Configuration) loadPaginationConf() {
+	c.Pagination.DefaultPageSize = utils.EnvOrDefaultInt32("PAGE_SIZE_DEFAULT", 50)
+	c.Pagination.MaxPageSize = utils.EnvOrDefaultInt32("PAGE_SIZE_MAX", 1000)
+	c.Pagination.TokenKey = utils.EnvOrDefault("PAGE_TOKEN_KEY", "")
+}
+
+func (c *CPagination) validate() error {
+	var errs []error
+	if c.DefaultPageSize < 1 {
+		errs = append(errs, fmt.Errorf("PAGE_SIZE_DEFAULT must be at least 1, got %d", c.DefaultPageSize))
+	}
+	if c.MaxPageSize < c.DefaultPageSize {
+		errs = append(errs, fmt.Errorf("PAGE_SIZE_MAX must be at least PAGE_SIZE_DEFAULT (%d), got %d", c.DefaultPageSize, c.MaxPageSize))
+	}
+	if c.TokenKey != "" && len(c.TokenKey) < 32 {
+		errs = append(errs, errors.New("PAGE_TOKEN_KEY must be at least 32 characters long"))
+	}
+	return errors.Join(errs...)
+}
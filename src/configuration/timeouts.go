@@ -0,0 +1,72 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/coderollers/go-utils"
+)
+
+// CTimeouts holds the request deadline settings for HTTP routes and GRPC methods.
+type CTimeouts struct {
+	// DefaultRequestTimeoutMs is the deadline applied to every request which has no
+	// route specific timeout. GRPC streams are exempt. Set to 0 to disable.
+	// Defaults to 30000.
+	DefaultRequestTimeoutMs int32
+	// RouteTimeoutsMs overrides the default timeout per route, where 0 disables
+	// the timeout of the route. HTTP routes are keyed by method and route
+	// template, such as "GET /v1/", GRPC methods by their full method name, such
+	// as "/protos.Greeter/SayHello".
+	RouteTimeoutsMs map[string]int32
+}
+
+func (c *Configuration) loadTimeoutsConf() {
+	c.Timeouts.DefaultRequestTimeoutMs = utils.EnvOrDefaultInt32("REQUEST_TIMEOUT_MS", 30000)
+	c.Timeouts.RouteTimeoutsMs = make(map[string]int32)
+
+	// ROUTE_TIMEOUTS has the format "GET /v1/=5000,/protos.Greeter/SayHello=2000"
+	for _, entry := range utils.EnvOrDefaultStringSlice("ROUTE_TIMEOUTS", ",", nil) {
+		idx := strings.LastIndex(entry, "=")
+		if idx < 0 {
+			log.Fatalf("invalid ROUTE_TIMEOUTS entry %q, expected <route>=<milliseconds>", entry)
+		}
+		ms, err := strconv.ParseInt(strings.TrimSpace(entry[idx+1:]), 10, 32)
+		if err != nil {
+			log.Fatalf("invalid ROUTE_TIMEOUTS entry %q: %s", entry, err.Error())
+		}
+		c.Timeouts.RouteTimeoutsMs[strings.TrimSpace(entry[:idx])] = int32(ms)
+	}
+}
+
+// RouteTimeoutMs returns the timeout configured for `route`, falling back to the
+// default request timeout.
+func (c *CTimeouts) RouteTimeoutMs(route string) int32 {
+	if ms, ok := c.RouteTimeoutsMs[route]; ok {
+		return ms
+	}
+	return c.DefaultRequestTimeoutMs
+}
+
+func (c *CTimeouts) validate() error {
+	var errs []error
+	if c.DefaultRequestTimeoutMs < 0 {
+		errs = append(errs, fmt.Errorf("REQUEST_TIMEOUT_MS must not be negative, got %d", c.DefaultRequestTimeoutMs))
+	}
+	routes := make([]string, 0, len(c.RouteTimeoutsMs))
+	for route := range c.RouteTimeoutsMs {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	for _, route := range routes {
+		if route == "" {
+			errs = append(errs, errors.New("ROUTE_TIMEOUTS entries must name a route"))
+		} else if ms := c.RouteTimeoutsMs[route]; ms < 0 {
+			errs = append(errs, fmt.Errorf("ROUTE_TIMEOUTS entry %q must not be negative, got %d", route, ms))
+		}
+	}
+	return errors.Join(errs...)
+}
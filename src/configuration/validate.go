@@ -0,0 +1,26 @@
+package configuration
+
+import (
+	"errors"
+)
+
+// Validate checks the configuration for values which would prevent the
+// microservice from working correctly. Production specific sanity checks are
+// done in main.
+func (c *Configuration) Validate() error {
+	var errs []error
+
+	errs = append(errs, c.Limiter.validate())
+	errs = append(errs, c.HttpServer.validate())
+	errs = append(errs, c.Grpc.validate())
+	errs = append(errs, c.validateManagement())
+	errs = append(errs, c.AccessLog.validate())
+	errs = append(errs, c.Errors.validate())
+	errs = append(errs, c.Jobs.validate())
+	errs = append(errs, c.Pagination.validate())
+	errs = append(errs, c.Timeouts.validate())
+	errs = append(errs, c.Maintenance.validate())
+	// TEMPLATE: Add more validation here
+
+	return errors.Join(errs...)
+}
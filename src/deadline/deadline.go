@@ -0,0 +1,171 @@
+package deadline
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers used to carry the remaining request time between services
+const (
+	// RequestTimeoutHeader holds the remaining time either in (fractional) seconds
+	// or as a Go duration string, such as "2.5" or "2500ms".
+	RequestTimeoutHeader = "Request-Timeout"
+	// GrpcTimeoutHeader holds the remaining time in the GRPC wire format, such as "2500m".
+	GrpcTimeoutHeader = "Grpc-Timeout"
+)
+
+// maxGrpcTimeoutDigits is the maximum length of a grpc-timeout value according to the GRPC spec
+const maxGrpcTimeoutDigits = 8
+
+// maxTimeoutSeconds is the longest timeout a time.Duration can hold, in seconds
+const maxTimeoutSeconds = float64(math.MaxInt64) / float64(time.Second)
+
+// FromRequest returns the timeout requested by the client through either the
+// Request-Timeout or grpc-timeout headers. If both are present, the smaller one
+// wins. ok is false if the client did not ask for a timeout.
+func FromRequest(r *http.Request) (timeout time.Duration, ok bool) {
+	if v := r.Header.Get(RequestTimeoutHeader); v != "" {
+		if t, err := ParseRequestTimeout(v); err == nil {
+			timeout, ok = t, true
+		}
+	}
+	if v := r.Header.Get(GrpcTimeoutHeader); v != "" {
+		if t, err := ParseGrpcTimeout(v); err == nil && (!ok || t < timeout) {
+			timeout, ok = t, true
+		}
+	}
+	return timeout, ok
+}
+
+// ParseRequestTimeout parses a Request-Timeout header value. The timeout must
+// be positive and fit in a time.Duration.
+func ParseRequestTimeout(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		// NaN fails every comparison, so it is rejected with the out of range values
+		nanoseconds := seconds * float64(time.Second)
+		if !(nanoseconds >= 1 && nanoseconds < float64(math.MaxInt64)) {
+			return 0, fmt.Errorf("request timeout must be positive and at most %.0f seconds, got %q", maxTimeoutSeconds, value)
+		}
+		return time.Duration(nanoseconds), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid request timeout %q", value)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("request timeout must be positive, got %q", value)
+	}
+	return d, nil
+}
+
+// ParseGrpcTimeout parses a grpc-timeout header value, as described in
+// https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-HTTP2.md
+func ParseGrpcTimeout(value string) (time.Duration, error) {
+	if len(value) < 2 || len(value) > maxGrpcTimeoutDigits+1 {
+		return 0, fmt.Errorf("invalid grpc timeout %q", value)
+	}
+	var unit time.Duration
+	switch value[len(value)-1] {
+	case 'H':
+		unit = time.Hour
+	case 'M':
+		unit = time.Minute
+	case 'S':
+		unit = time.Second
+	case 'm':
+		unit = time.Millisecond
+	case 'u':
+		unit = time.Microsecond
+	case 'n':
+		unit = time.Nanosecond
+	default:
+		return 0, fmt.Errorf("invalid grpc timeout unit in %q", value)
+	}
+	amount, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || amount <= 0 {
+		return 0, fmt.Errorf("invalid grpc timeout %q", value)
+	}
+	// 8 digits of hours do not fit in a time.Duration
+	if amount > math.MaxInt64/int64(unit) {
+		return 0, fmt.Errorf("grpc timeout %q is out of range", value)
+	}
+	return time.Duration(amount) * unit, nil
+}
+
+// FormatGrpcTimeout encodes `d` in the grpc-timeout wire format, using the
+// finest unit which fits within the 8 digits allowed by the spec.
+func FormatGrpcTimeout(d time.Duration) string {
+	if d <= 0 {
+		return "1n"
+	}
+	for _, u := range []struct {
+		unit   time.Duration
+		suffix string
+	}{
+		{time.Nanosecond, "n"},
+		{time.Microsecond, "u"},
+		{time.Millisecond, "m"},
+		{time.Second, "S"},
+		{time.Minute, "M"},
+	} {
+		// Round up so that the callee never gets more time than we have left
+		amount := (d + u.unit - 1) / u.unit
+		if amount < 1e8 {
+			return strconv.FormatInt(int64(amount), 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(int64((d+time.Hour-1)/time.Hour), 10) + "H"
+}
+
+// Propagate sets the timeout headers on `header` to the time remaining until the
+// deadline of `ctx`. It returns context.DeadlineExceeded if the deadline has
+// already passed, so that the outbound call can be skipped. Headers are left
+// untouched if `ctx` has no deadline.
+func Propagate(ctx context.Context, header http.Header) error {
+	d, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	remaining := time.Until(d)
+	if remaining <= 0 {
+		return context.DeadlineExceeded
+	}
+	header.Set(RequestTimeoutHeader, strconv.FormatFloat(remaining.Seconds(), 'f', 3, 64))
+	header.Set(GrpcTimeoutHeader, FormatGrpcTimeout(remaining))
+	return nil
+}
+
+// Transport is an http.RoundTripper which propagates the remaining time of the
+// request context to the called service. Use it for all outbound HTTP clients:
+//
+//	client := &http.Client{Transport: &deadline.Transport{}}
+//
+// Outbound GRPC calls need no special handling, since GRPC clients already
+// propagate context deadlines through the grpc-timeout header.
+type Transport struct {
+	// Base is the underlying RoundTripper. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if _, ok := req.Context().Deadline(); !ok {
+		return base.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the original request
+	req = req.Clone(req.Context())
+	if err := Propagate(req.Context(), req.Header); err != nil {
+		return nil, fmt.Errorf("not sending request to %s: %w", req.URL.Host, err)
+	}
+	return base.RoundTrip(req)
+}
@@ -1,5 +1,5 @@
-// Package docs GENERATED BY SWAG; DO NOT EDIT
-// This file was generated by swaggo/swag
+// Code generated by swaggo/swag. DO NOT EDIT.
+
 package docs
 
 import "github.com/swaggo/swag"
@@ -18,26 +18,56 @@ const docTemplate = `{
     "paths": {
         "/v1/": {
             "get": {
-                "description": "Sample GET handler",
+                "description": "Sample GET handler. Failures are rendered as problem details instead of the JSONFailureResult\nenvelope if the client accepts application/problem+json, or if ERROR_FORMAT is \"problem\".\nSuccessful responses are rendered in the media type negotiated with the Accept header, and\nindented if the ` + "`" + `pretty` + "`" + ` query parameter is set.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/problem+json",
+                    "application/yaml",
+                    "application/msgpack"
                 ],
                 "summary": "Sample GET handler",
                 "operationId": "index-get",
+                "parameters": [
+                    {
+                        "maxLength": 32,
+                        "type": "string",
+                        "description": "The name to greet",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Indent the JSON response",
+                        "name": "pretty",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "Positive response",
                         "schema": {
-                            "$ref": "#/definitions/models.JSONSuccessResult"
+                            "$ref": "#/definitions/models.JSONSuccessResult-v1_IndexResponse"
                         }
                     },
                     "400": {
                         "description": "The request data could not be processed",
                         "schema": {
-                            "$ref": "#/definitions/models.JSONFailureResult"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.JSONFailureResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ValidationErrors"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "404": {
@@ -46,6 +76,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.JSONNotFoundResult"
                         }
                     },
+                    "406": {
+                        "description": "None of the accepted media types can be produced",
+                        "schema": {
+                            "$ref": "#/definitions/models.JSONFailureResult"
+                        }
+                    },
                     "500": {
                         "description": "An internal error has occurred, most likely due to an uncaught exception",
                         "schema": {
@@ -57,12 +93,466 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.JSONFailureResult"
                         }
+                    },
+                    "default": {
+                        "description": "Any failure, when rendered as application/problem+json",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/greetings": {
+            "post": {
+                "description": "Sample handler written as a typed function and adapted to Gin with endpoint.Handle, which takes\ncare of binding, validation, tracing and rendering the response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Sample typed POST handler",
+                "operationId": "greeting-post",
+                "parameters": [
+                    {
+                        "description": "The name to greet",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.GreetingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Positive response",
+                        "schema": {
+                            "$ref": "#/definitions/models.JSONSuccessResult-v1_GreetingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "The request data could not be processed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.JSONFailureResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ValidationErrors"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "An internal error has occurred, most likely due to an uncaught exception",
+                        "schema": {
+                            "$ref": "#/definitions/models.JSONFailureResult"
+                        }
+                    },
+                    "default": {
+                        "description": "Any failure, when rendered as application/problem+json",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/greetings/async": {
+            "post": {
+                "description": "Sample handler submitting its work as a job. It answers at once with 202 Accepted and a Location\nheader pointing to the job, which holds the GreetingResponse once it has succeeded.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Sample asynchronous POST handler",
+                "operationId": "greeting-async-post",
+                "parameters": [
+                    {
+                        "description": "The name to greet",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.GreetingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "The job was queued",
+                        "schema": {
+                            "$ref": "#/definitions/models.JSONAcceptedResult-jobs_Job"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "The URL of the job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "The request data could not be processed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.JSONFailureResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ValidationErrors"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "429": {
+                        "description": "The job queue is full",
+                        "schema": {
+                            "$ref": "#/definitions/models.JSONFailureResult"
+                        }
+                    },
+                    "default": {
+                        "description": "Any failure, when rendered as application/problem+json",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs": {
+            "get": {
+                "description": "Lists the asynchronous jobs, newest first unless sorted otherwise. The next page is requested by\npassing next_page_token as page_token, with the same sort and filter.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "List the jobs",
+                "operationId": "jobs-get",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "The maximum number of jobs to return",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The next_page_token of the previous page",
+                        "name": "page_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-created_at,name",
+                        "description": "Comma separated fields to sort by, descending when prefixed with -",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Conditions joined with AND, such as: state = running AND progress \u003e= 50",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "A page of jobs",
+                        "schema": {
+                            "$ref": "#/definitions/models.JSONPageResult-jobs_Job"
+                        }
+                    },
+                    "400": {
+                        "description": "The request data could not be processed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.JSONFailureResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ValidationErrors"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "default": {
+                        "description": "Any failure, when rendered as application/problem+json",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs/{id}": {
+            "get": {
+                "description": "Returns the state, progress and result of an asynchronous job. The URL is given by the Location\nheader of the requests answered with 202 Accepted. Finished jobs are kept for JOBS_RETENTION_SEC.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Get a job",
+                "operationId": "job-get",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The ID of the job",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The job",
+                        "schema": {
+                            "$ref": "#/definitions/models.JSONSuccessResult-jobs_Job"
+                        }
+                    },
+                    "400": {
+                        "description": "The request data could not be processed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.JSONFailureResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ValidationErrors"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The job was not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.JSONFailureResult"
+                        }
+                    },
+                    "default": {
+                        "description": "Any failure, when rendered as application/problem+json",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs/{id}/cancel": {
+            "post": {
+                "description": "Cancels an asynchronous job. A queued job is cancelled at once, a running one as soon as it\nstops, so the returned job may still be running.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "summary": "Cancel a job",
+                "operationId": "job-cancel-post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "The ID of the job",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The job",
+                        "schema": {
+                            "$ref": "#/definitions/models.JSONSuccessResult-jobs_Job"
+                        }
+                    },
+                    "400": {
+                        "description": "The request data could not be processed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.JSONFailureResult"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ValidationErrors"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "The job was not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.JSONFailureResult"
+                        }
+                    },
+                    "409": {
+                        "description": "The job is already done",
+                        "schema": {
+                            "$ref": "#/definitions/models.JSONFailureResult"
+                        }
+                    },
+                    "default": {
+                        "description": "Any failure, when rendered as application/problem+json",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDetails"
+                        }
                     }
                 }
             }
         }
     },
     "definitions": {
+        "jobs.Failure": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "jobs.Job": {
+            "type": "object",
+            "properties": {
+                "correlation_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error describes why a job failed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/jobs.Failure"
+                        }
+                    ]
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "progress": {
+                    "description": "Progress is the completion percentage reported by the job",
+                    "type": "integer"
+                },
+                "result": {
+                    "description": "Result is the value returned by a succeeded job"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "enum": [
+                        "queued",
+                        "running",
+                        "succeeded",
+                        "failed",
+                        "cancelled"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/jobs.State"
+                        }
+                    ]
+                }
+            }
+        },
+        "jobs.State": {
+            "type": "string",
+            "enum": [
+                "queued",
+                "running",
+                "succeeded",
+                "failed",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "StateQueued",
+                "StateRunning",
+                "StateSucceeded",
+                "StateFailed",
+                "StateCancelled"
+            ]
+        },
+        "models.FieldViolation": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "name"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "name is required"
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "required"
+                }
+            }
+        },
+        "models.JSONAcceptedResult-jobs_Job": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 202
+                },
+                "correlation_id": {
+                    "type": "string",
+                    "example": "705e4dcb-3ecd-24f3-3a35-3e926e4bded5"
+                },
+                "data": {
+                    "$ref": "#/definitions/jobs.Job"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123-456-789-abc-def"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Accepted"
+                }
+            }
+        },
         "models.JSONFailureResult": {
             "type": "object",
             "properties": {
@@ -79,6 +569,24 @@ const docTemplate = `{
                     "type": "string",
                     "example": "There was an error processing the request"
                 },
+                "error_code": {
+                    "type": "string",
+                    "example": "INVALID_ARGUMENT"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "The request is invalid"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "retryable": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "stacktrace": {
                     "type": "string"
                 }
@@ -98,7 +606,7 @@ const docTemplate = `{
                 "data": {}
             }
         },
-        "models.JSONSuccessResult": {
+        "models.JSONPageResult-jobs_Job": {
             "type": "object",
             "properties": {
                 "code": {
@@ -109,12 +617,199 @@ const docTemplate = `{
                     "type": "string",
                     "example": "705e4dcb-3ecd-24f3-3a35-3e926e4bded5"
                 },
-                "data": {},
+                "data": {
+                    "$ref": "#/definitions/models.Page-jobs_Job"
+                },
                 "message": {
                     "type": "string",
                     "example": "Success"
                 }
             }
+        },
+        "models.JSONSuccessResult-jobs_Job": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "correlation_id": {
+                    "type": "string",
+                    "example": "705e4dcb-3ecd-24f3-3a35-3e926e4bded5"
+                },
+                "data": {
+                    "$ref": "#/definitions/jobs.Job"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success"
+                }
+            }
+        },
+        "models.JSONSuccessResult-v1_GreetingResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "correlation_id": {
+                    "type": "string",
+                    "example": "705e4dcb-3ecd-24f3-3a35-3e926e4bded5"
+                },
+                "data": {
+                    "$ref": "#/definitions/v1.GreetingResponse"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success"
+                }
+            }
+        },
+        "models.JSONSuccessResult-v1_IndexResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "correlation_id": {
+                    "type": "string",
+                    "example": "705e4dcb-3ecd-24f3-3a35-3e926e4bded5"
+                },
+                "data": {
+                    "$ref": "#/definitions/v1.IndexResponse"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Success"
+                }
+            }
+        },
+        "models.Page-jobs_Job": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jobs.Job"
+                    }
+                },
+                "next_page_token": {
+                    "type": "string",
+                    "example": "eyJvZmZzZXQiOjUwfQ"
+                },
+                "total_size": {
+                    "description": "TotalSize is the size of the whole list, if it is cheap to compute",
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "models.ProblemDetails": {
+            "type": "object",
+            "properties": {
+                "correlation_id": {
+                    "description": "Extension members",
+                    "type": "string",
+                    "example": "705e4dcb-3ecd-24f3-3a35-3e926e4bded5"
+                },
+                "data": {},
+                "detail": {
+                    "type": "string",
+                    "example": "The request is invalid"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "There was an error processing the request"
+                },
+                "error_code": {
+                    "type": "string",
+                    "example": "INVALID_ARGUMENT"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldViolation"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/v1/"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "retryable": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "stacktrace": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Bad Request"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "models.ValidationErrors": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldViolation"
+                    }
+                }
+            }
+        },
+        "v1.GreetingRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "description": "Name is the name to greet",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "John"
+                }
+            }
+        },
+        "v1.GreetingResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Hello there, John"
+                }
+            }
+        },
+        "v1.IndexResponse": {
+            "type": "object",
+            "properties": {
+                "Greeting": {
+                    "type": "string",
+                    "example": "Hello, John"
+                },
+                "Motto": {
+                    "type": "string",
+                    "example": "Hello world!"
+                }
+            }
         }
     }
 }`
@@ -129,6 +824,8 @@ var SwaggerInfo = &swag.Spec{
 	Description:      "",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
+	LeftDelim:        "{{",
+	RightDelim:       "}}",
 }
 
 func init() {
@@ -0,0 +1,73 @@
+package health
+
+import (
+	"sync"
+	"sync/atomic"
+
+	grpcHealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"my-microservice/metrics"
+)
+
+var (
+	mu          sync.Mutex
+	ready       atomic.Bool
+	maintenance atomic.Bool
+	grpcServer  = grpcHealth.NewServer()
+)
+
+func init() {
+	// Not ready until all components have been started
+	grpcServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// SetReady marks the microservice as ready or not ready to receive traffic, on
+// both the HTTP readiness endpoint and the GRPC health service.
+func SetReady(isReady bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	ready.Store(isReady)
+	publish()
+}
+
+// Ready reports whether the microservice is ready to receive traffic. It is
+// never ready in maintenance mode.
+func Ready() bool {
+	return ready.Load() && !maintenance.Load()
+}
+
+// SetMaintenance turns the maintenance mode on or off. In maintenance mode the
+// microservice reports not-ready and rejects requests, see Maintenance.
+func SetMaintenance(enabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	maintenance.Store(enabled)
+	if enabled {
+		metrics.MaintenanceMode.Set(1)
+	} else {
+		metrics.MaintenanceMode.Set(0)
+	}
+	publish()
+}
+
+// Maintenance reports whether the maintenance mode is active
+func Maintenance() bool {
+	return maintenance.Load()
+}
+
+// publish updates the status of the GRPC health service. Must be called with mu
+// held.
+func publish() {
+	if Ready() {
+		grpcServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	} else {
+		grpcServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+// GrpcServer returns the implementation of the standard GRPC health service,
+// reporting the same readiness as the HTTP endpoint.
+func GrpcServer() *grpcHealth.Server {
+	return grpcServer
+}
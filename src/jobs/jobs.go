@@ -0,0 +1,384 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/coderollers/go-logger"
+	goErrors "github.com/go-errors/errors"
+	"github.com/google/uuid"
+
+	"my-microservice/apperrors"
+	"my-microservice/configuration"
+	"my-microservice/lifecycle"
+	"my-microservice/metrics"
+	"my-microservice/tasks"
+)
+
+// State is the state of a job
+type State string
+
+const (
+	StateQueued    State = "queued"
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+	StateCancelled State = "cancelled"
+)
+
+// Done tells whether the state is final
+func (s State) Done() bool {
+	return s == StateSucceeded || s == StateFailed || s == StateCancelled
+}
+
+// Job describes a unit of work run asynchronously by the worker pool
+type Job struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	State State  `json:"state" enums:"queued,running,succeeded,failed,cancelled"`
+	// Progress is the completion percentage reported by the job
+	Progress int `json:"progress"`
+	// Result is the value returned by a succeeded job
+	Result interface{} `json:"result,omitempty"`
+	// Error describes why a job failed
+	Error         *Failure   `json:"error,omitempty"`
+	CorrelationId string     `json:"correlation_id,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
+
+// Failure is the public part of the error of a failed job
+type Failure struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Func is the work of a job. It must return once ctx is cancelled, which
+// happens when the job is cancelled or the service shuts down. The progress
+// can be reported as a percentage with `progress`. The result must be
+// serializable to JSON.
+type Func func(ctx context.Context, progress func(percent int)) (interface{}, error)
+
+// entry is a job which is queued or running
+type entry struct {
+	id      string
+	name    string
+	fn      Func
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running bool
+	// done is closed when the job is done
+	done chan struct{}
+}
+
+var (
+	// mu serializes the changes of the jobs, and guards the variables below
+	mu        sync.Mutex
+	store     Store
+	queue     chan *entry
+	pending   = make(map[string]*entry)
+	accepting bool
+	workers   sync.WaitGroup
+)
+
+// Component runs the jobs on a pool of CJobs.Workers workers, and keeps them in
+// `s`. When stopped, the queued jobs are cancelled and the running ones are
+// given CJobs.StopTimeoutSec to finish before being cancelled.
+func Component(s Store) lifecycle.Component {
+	return lifecycle.Component{
+		Name: "jobs",
+		Start: func(_ context.Context) error {
+			start(s)
+			return nil
+		},
+		Stop: stop,
+	}
+}
+
+func start(s Store) {
+	conf := configuration.AppConfig()
+
+	mu.Lock()
+	defer mu.Unlock()
+	store = s
+	queue = make(chan *entry, conf.Jobs.QueueSize)
+	accepting = true
+	for i := int32(0); i < conf.Jobs.Workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for e := range queue {
+				run(e)
+			}
+		}()
+	}
+}
+
+func stop(ctx context.Context) error {
+	log := logger.SugaredLogger().With("package", "jobs")
+	conf := configuration.AppConfig()
+
+	mu.Lock()
+	accepting = false
+	close(queue)
+	for _, e := range pending {
+		if !e.running {
+			finish(e, StateCancelled, nil, nil)
+		}
+	}
+	mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(time.Duration(conf.Jobs.StopTimeoutSec) * time.Second)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return nil
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	// The cancelled jobs are recorded by the workers once their Func returns
+	mu.Lock()
+	log.Warnf("Cancelling %d jobs still running at shutdown", len(pending))
+	for _, e := range pending {
+		e.cancel()
+	}
+	mu.Unlock()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Submit queues `fn` as a job named `name` and returns it. The correlation ID is
+// taken from `ctx`, which can also be a *gin.Context, but the job is not
+// cancelled with it. Fails with apperrors.ErrResourceExhausted when the queue is
+// full and apperrors.ErrUnavailable when the jobs are not running.
+func Submit(ctx context.Context, name string, fn Func) (Job, error) {
+	correlationId, _ := ctx.Value(configuration.CorrelationIdKey).(string)
+	job := Job{
+		Id:            uuid.NewString(),
+		Name:          name,
+		State:         StateQueued,
+		CorrelationId: correlationId,
+		CreatedAt:     time.Now(),
+	}
+	jobCtx, cancel := context.WithCancel(context.WithValue(context.Background(), configuration.CorrelationIdKey, correlationId))
+	e := &entry{id: job.Id, name: name, fn: fn, ctx: jobCtx, cancel: cancel, done: make(chan struct{})}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !accepting {
+		cancel()
+		return Job{}, apperrors.ErrUnavailable.WithMessage("Jobs are not accepted at the moment, please retry later")
+	}
+	if len(queue) == cap(queue) {
+		cancel()
+		return Job{}, apperrors.ErrResourceExhausted.WithMessage("The job queue is full, please retry later")
+	}
+	if err := store.Save(ctx, job); err != nil {
+		cancel()
+		return Job{}, err
+	}
+	// Cannot block: Submit is the only sender, it sends under mu after checking
+	// there is room, and the workers only ever take entries out
+	queue <- e
+	pending[job.Id] = e
+	metrics.JobsQueued.Inc()
+	return job, nil
+}
+
+// Get returns the job with the given ID
+func Get(ctx context.Context, id string) (Job, error) {
+	if err := ready(); err != nil {
+		return Job{}, err
+	}
+	return store.Get(ctx, id)
+}
+
+// List returns all jobs, newest first
+func List(ctx context.Context) ([]Job, error) {
+	if err := ready(); err != nil {
+		return nil, err
+	}
+	return store.List(ctx)
+}
+
+// Cancel cancels a job. A queued job is cancelled at once, a running one once
+// its Func returns. Fails with apperrors.ErrFailedPrecondition if the job is
+// already done.
+func Cancel(ctx context.Context, id string) (Job, error) {
+	if err := ready(); err != nil {
+		return Job{}, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	job, err := store.Get(ctx, id)
+	if err != nil {
+		return Job{}, err
+	}
+	e, ok := pending[id]
+	if job.State.Done() || !ok {
+		return job, apperrors.ErrFailedPrecondition.WithMessage("The job is already done").WithMetadata("state", string(job.State))
+	}
+	if !e.running {
+		finish(e, StateCancelled, nil, nil)
+		return store.Get(ctx, id)
+	}
+	e.cancel()
+	return job, nil
+}
+
+// Wait blocks until the job is done or ctx expires, and returns its latest state
+func Wait(ctx context.Context, id string) (Job, error) {
+	if err := ready(); err != nil {
+		return Job{}, err
+	}
+
+	mu.Lock()
+	e, ok := pending[id]
+	mu.Unlock()
+	if ok {
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+		}
+	}
+	return store.Get(context.Background(), id)
+}
+
+// Delete removes a job which is done. Fails with apperrors.ErrFailedPrecondition
+// if the job is not done.
+func Delete(ctx context.Context, id string) error {
+	if err := ready(); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	job, err := store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, ok := pending[id]; ok {
+		return apperrors.ErrFailedPrecondition.WithMessage("The job is not done, cancel it first").WithMetadata("state", string(job.State))
+	}
+	return store.Delete(ctx, id)
+}
+
+func ready() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if store == nil {
+		return apperrors.ErrUnavailable.WithMessage("The jobs are not running")
+	}
+	return nil
+}
+
+// run runs a job on the calling worker, unless it was cancelled while queued
+func run(e *entry) {
+	metrics.JobsQueued.Dec()
+	if !markRunning(e) {
+		return
+	}
+	done := tasks.Track(e.ctx, "Job "+e.name)
+	defer done()
+	metrics.JobsRunning.Inc()
+	defer metrics.JobsRunning.Dec()
+
+	result, err := call(e)
+
+	mu.Lock()
+	defer mu.Unlock()
+	switch {
+	case err == nil:
+		finish(e, StateSucceeded, result, nil)
+	case e.ctx.Err() != nil:
+		finish(e, StateCancelled, nil, nil)
+	default:
+		finish(e, StateFailed, nil, err)
+	}
+}
+
+func markRunning(e *entry) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if e.ctx.Err() != nil {
+		return false
+	}
+	e.running = true
+	now := time.Now()
+	update(e.id, func(job *Job) {
+		job.State = StateRunning
+		job.StartedAt = &now
+	})
+	return true
+}
+
+// call runs the Func of a job, converting panics into errors
+func call(e *entry) (result interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			wrapped := goErrors.Wrap(p, 2)
+			metrics.PanicsRecovered.WithLabelValues("jobs").Inc()
+			logger.SugaredLogger().WithContextCorrelationId(e.ctx).With("package", "jobs").
+				Errorw("Panic recovered", "panic", wrapped.Error(), "stack", string(wrapped.Stack()), "job_id", e.id, "job", e.name)
+			err = apperrors.ErrInternal.Wrap(wrapped)
+		}
+	}()
+	return e.fn(e.ctx, func(percent int) {
+		if percent < 0 {
+			percent = 0
+		} else if percent > 100 {
+			percent = 100
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		update(e.id, func(job *Job) { job.Progress = percent })
+	})
+}
+
+// finish records the final state of a job. The caller must hold mu.
+func finish(e *entry, state State, result interface{}, err error) {
+	delete(pending, e.id)
+	e.cancel()
+	defer close(e.done)
+	now := time.Now()
+	update(e.id, func(job *Job) {
+		job.State = state
+		job.FinishedAt = &now
+		switch state {
+		case StateSucceeded:
+			job.Progress = 100
+			job.Result = result
+		case StateFailed:
+			appErr := apperrors.From(err)
+			job.Error = &Failure{Code: appErr.Code, Message: appErr.Message}
+			logger.SugaredLogger().WithContextCorrelationId(e.ctx).With("package", "jobs").
+				Warnw("Job failed", "job_id", e.id, "job", e.name, "error", err.Error())
+		}
+	})
+	metrics.JobsFinished.WithLabelValues(e.name, string(state)).Inc()
+}
+
+// update changes a job in the store. The caller must hold mu.
+func update(id string, change func(job *Job)) {
+	job, err := store.Get(context.Background(), id)
+	if err == nil {
+		change(&job)
+		err = store.Save(context.Background(), job)
+	}
+	if err != nil {
+		logger.SugaredLogger().With("package", "jobs").Errorw("Cannot update the job", "job_id", id, "error", err.Error())
+	}
+}
@@ -0,0 +1,89 @@
+package jobs
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"my-microservice/apperrors"
+)
+
+// Store persists the jobs. The in-memory store is used by default, implement
+// this interface to keep the jobs in a database shared by all replicas.
+// TEMPLATE: Add a durable store
+type Store interface {
+	// Save creates or replaces a job
+	Save(ctx context.Context, job Job) error
+	// Get returns the job with the given ID, or apperrors.ErrNotFound
+	Get(ctx context.Context, id string) (Job, error)
+	// List returns all jobs, newest first
+	List(ctx context.Context) ([]Job, error)
+	// Delete removes the job with the given ID, or returns apperrors.ErrNotFound
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore keeps the jobs in memory. Finished jobs are removed once they
+// are older than the retention period.
+type MemoryStore struct {
+	mu        sync.Mutex
+	jobs      map[string]Job
+	retention time.Duration
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore(retention time.Duration) *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]Job), retention: retention}
+}
+
+func (s *MemoryStore) Save(_ context.Context, job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune()
+	s.jobs[job.Id] = job
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune()
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, apperrors.ErrNotFound.WithMessage("The job was not found").WithMetadata("job_id", id)
+	}
+	return job, nil
+}
+
+func (s *MemoryStore) List(_ context.Context) ([]Job, error) {
+	s.mu.Lock()
+	s.prune()
+	list := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		list = append(list, job)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[id]; !ok {
+		return apperrors.ErrNotFound.WithMessage("The job was not found").WithMetadata("job_id", id)
+	}
+	delete(s.jobs, id)
+	return nil
+}
+
+// prune removes the finished jobs older than the retention period. The caller
+// must hold the lock.
+func (s *MemoryStore) prune() {
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && time.Since(*job.FinishedAt) > s.retention {
+			delete(s.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/coderollers/go-logger"
+)
+
+// Component is a part of the microservice with a managed lifecycle, such as a
+// server, a database pool or a background worker.
+type Component struct {
+	// Name identifies the component in logs, errors and dependency lists
+	Name string
+	// DependsOn lists the names of the components which must be started before
+	// this one, and stopped after it.
+	DependsOn []string
+	// Start brings the component up. It must not block for longer than needed to
+	// initialize, long-running work has to be started in its own goroutine. Optional.
+	Start func(ctx context.Context) error
+	// Stop shuts the component down, blocking until it is done or ctx expires. Optional.
+	Stop func(ctx context.Context) error
+	// StopTimeout bounds the time given to Stop. If zero, Stop gets whatever is
+	// left of the overall shutdown timeout.
+	StopTimeout time.Duration
+}
+
+// StopError reports a component which failed to stop cleanly
+type StopError struct {
+	Component string
+	Err       error
+}
+
+func (e *StopError) Error() string {
+	return fmt.Sprintf("component %q failed to stop: %s", e.Component, e.Err.Error())
+}
+
+func (e *StopError) Unwrap() error {
+	return e.Err
+}
+
+// Manager starts registered components in dependency order and stops them in
+// reverse order.
+type Manager struct {
+	mu              sync.Mutex
+	components      []*Component
+	started         []*Component
+	rollbackTimeout time.Duration
+}
+
+// New creates an empty Manager. `rollbackTimeout` bounds the time given to
+// stop the started components when Start fails, such as the shutdown timeout.
+func New(rollbackTimeout time.Duration) *Manager {
+	return &Manager{rollbackTimeout: rollbackTimeout}
+}
+
+// Register adds a component to the manager. Components must be registered
+// before Start is called.
+func (m *Manager) Register(c Component) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if c.Name == "" {
+		return errors.New("component name must not be empty")
+	}
+	for _, existing := range m.components {
+		if existing.Name == c.Name {
+			return fmt.Errorf("component %q is already registered", c.Name)
+		}
+	}
+	m.components = append(m.components, &c)
+	return nil
+}
+
+// Start starts all components so that every component is started after its
+// dependencies. If a component fails to start, the ones already started are
+// stopped again within the rollback timeout and the error is returned.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	ordered, err := m.order()
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	log := logger.SugaredLogger().With("package", "lifecycle")
+	for _, c := range ordered {
+		log.Debugf("Starting component %s", c.Name)
+		if c.Start != nil {
+			if err := c.Start(ctx); err != nil {
+				startErr := fmt.Errorf("component %q failed to start: %w", c.Name, err)
+				rollbackCtx, cancel := context.WithTimeout(context.Background(), m.rollbackTimeout)
+				defer cancel()
+				if stopErr := m.Stop(rollbackCtx); stopErr != nil {
+					return errors.Join(startErr, stopErr)
+				}
+				return startErr
+			}
+		}
+		m.mu.Lock()
+		m.started = append(m.started, c)
+		m.mu.Unlock()
+		log.Infof("Component %s started", c.Name)
+	}
+	return nil
+}
+
+// Stop stops all started components in reverse start order. Every component is
+// given its own StopTimeout, bounded by the deadline of ctx. Components are
+// stopped even if some of them fail, and all failures are returned as
+// StopErrors.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	started := m.started
+	m.started = nil
+	m.mu.Unlock()
+
+	log := logger.SugaredLogger().With("package", "lifecycle")
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		c := started[i]
+		if c.Stop == nil {
+			continue
+		}
+
+		log.Infof("Stopping component %s", c.Name)
+		if err := stopComponent(ctx, c); err != nil {
+			log.Errorf("Component %s failed to stop: %s", c.Name, err.Error())
+			errs = append(errs, &StopError{Component: c.Name, Err: err})
+			continue
+		}
+		log.Infof("Component %s stopped", c.Name)
+	}
+	return errors.Join(errs...)
+}
+
+// stopComponent runs the Stop hook of `c` within its timeout. The hook keeps
+// running in the background if it ignores the deadline, but is reported as failed.
+func stopComponent(ctx context.Context, c *Component) error {
+	if c.StopTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.StopTimeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Stop(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// order sorts the components topologically, keeping registration order among
+// components which do not depend on each other.
+func (m *Manager) order() ([]*Component, error) {
+	byName := make(map[string]*Component, len(m.components))
+	for _, c := range m.components {
+		byName[c.Name] = c
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(m.components))
+	ordered := make([]*Component, 0, len(m.components))
+
+	var visit func(c *Component) error
+	visit = func(c *Component) error {
+		switch state[c.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle detected at component %q", c.Name)
+		}
+		state[c.Name] = visiting
+		for _, name := range c.DependsOn {
+			dep, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("component %q depends on unknown component %q", c.Name, name)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[c.Name] = visited
+		ordered = append(ordered, c)
+		return nil
+	}
+
+	for _, c := range m.components {
+		if err := visit(c); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,142 @@
+package limiter
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"my-microservice/configuration"
+	"my-microservice/metrics"
+)
+
+// Priority classifies requests for load shedding purposes
+type Priority int
+
+const (
+	// PriorityNormal requests are rejected when the concurrency limit is reached
+	PriorityNormal Priority = iota
+	// PriorityCritical requests, such as health checks and admin traffic, are never shed
+	PriorityCritical
+)
+
+// Outcome describes how a request which held a slot has completed
+type Outcome int
+
+const (
+	// OutcomeSuccess requests have their latency sampled to adjust the limit
+	OutcomeSuccess Outcome = iota
+	// OutcomeDropped requests failed because of an overloaded dependency and always reduce the limit
+	OutcomeDropped
+	// OutcomeIgnored requests, such as long-lived streams, do not influence the limit
+	OutcomeIgnored
+)
+
+// longRttDecay controls how fast the long-term latency average follows new samples
+const longRttDecay = 0.05
+
+// Limiter is an adaptive concurrency limiter using the AIMD (additive increase,
+// multiplicative decrease) algorithm. The limit grows by one while requests
+// complete within the tolerated latency and is cut by the backoff ratio as soon
+// as a request is slower than the tolerance or is reported as dropped.
+type Limiter struct {
+	mu sync.Mutex
+
+	name             string
+	limit            float64
+	minLimit         float64
+	maxLimit         float64
+	backoffRatio     float64
+	latencyTolerance float64
+	latencyThreshold time.Duration
+
+	inflight int
+	longRtt  float64
+}
+
+// New creates a Limiter named `name` using the limiter settings of the
+// application configuration. The name is used as the metrics label.
+func New(name string) *Limiter {
+	conf := configuration.AppConfig().Limiter
+
+	l := &Limiter{
+		name:             name,
+		limit:            float64(conf.InitialLimit),
+		minLimit:         float64(conf.MinLimit),
+		maxLimit:         float64(conf.MaxLimit),
+		backoffRatio:     float64(conf.BackoffPercent) / 100,
+		latencyTolerance: float64(conf.LatencyTolerancePercent) / 100,
+		latencyThreshold: time.Duration(conf.LatencyThresholdMs) * time.Millisecond,
+	}
+	metrics.LimiterLimit.WithLabelValues(name).Set(l.limit)
+	metrics.LimiterInflight.WithLabelValues(name).Set(0)
+	return l
+}
+
+// Acquire attempts to reserve a slot for a request of the given priority. If ok
+// is false the request must be rejected immediately. Otherwise, release must be
+// called when the request completes. Only the first call has any effect.
+func (l *Limiter) Acquire(priority Priority) (release func(outcome Outcome), ok bool) {
+	l.mu.Lock()
+	if priority != PriorityCritical && float64(l.inflight) >= math.Floor(l.limit) {
+		l.mu.Unlock()
+		metrics.LimiterShed.WithLabelValues(l.name).Inc()
+		return nil, false
+	}
+	l.inflight++
+	metrics.LimiterInflight.WithLabelValues(l.name).Set(float64(l.inflight))
+	l.mu.Unlock()
+
+	start := time.Now()
+	var once sync.Once
+	return func(outcome Outcome) {
+		once.Do(func() {
+			l.release(priority, time.Since(start), outcome)
+		})
+	}, true
+}
+
+// Limit returns the current concurrency limit
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// Inflight returns the number of requests currently holding a slot
+func (l *Limiter) Inflight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inflight
+}
+
+func (l *Limiter) release(priority Priority, rtt time.Duration, outcome Outcome) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	inflight := l.inflight
+	l.inflight--
+	metrics.LimiterInflight.WithLabelValues(l.name).Set(float64(l.inflight))
+
+	// Critical traffic bypasses the limit, so it must not influence it either
+	if priority == PriorityCritical || outcome == OutcomeIgnored {
+		return
+	}
+
+	sample := float64(rtt)
+	if l.longRtt == 0 {
+		l.longRtt = sample
+	}
+	congested := outcome == OutcomeDropped || rtt > l.latencyThreshold || sample > l.longRtt*l.latencyTolerance
+	l.longRtt = l.longRtt*(1-longRttDecay) + sample*longRttDecay
+
+	switch {
+	case congested:
+		l.limit = math.Max(l.minLimit, l.limit*l.backoffRatio)
+	case float64(inflight)*2 >= l.limit:
+		// Only grow the limit while it is actually being used
+		l.limit = math.Min(l.maxLimit, l.limit+1)
+	default:
+		return
+	}
+	metrics.LimiterLimit.WithLabelValues(l.name).Set(math.Floor(l.limit))
+}
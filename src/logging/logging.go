@@ -0,0 +1,257 @@
+package logging
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coderollers/go-logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LoggerNameKey is the log field which names a logger, such as
+// `With("package", "handlers")`. Log levels can be set per logger name.
+const LoggerNameKey = "package"
+
+// LevelState describes the log level of the global logger or of a named logger
+type LevelState struct {
+	Logger    string     `json:"logger,omitempty" example:"handlers"`
+	Level     string     `json:"level" example:"debug"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Levels describes the global log level and the per logger overrides
+type Levels struct {
+	Global  LevelState   `json:"global"`
+	Loggers []LevelState `json:"loggers"`
+}
+
+// Caller identifies who took an administrative action, for the audit log
+type Caller struct {
+	Protocol      string `json:"protocol"`
+	Address       string `json:"address"`
+	UserAgent     string `json:"user_agent,omitempty"`
+	CorrelationId string `json:"correlation_id,omitempty"`
+}
+
+// override is a log level set at runtime. The global level is stored under the
+// empty name.
+type override struct {
+	level     zapcore.Level
+	expiresAt time.Time
+	timer     *time.Timer
+	// previous is the override restored when this one expires, nil to restore the default
+	previous *override
+}
+
+var (
+	mu           sync.Mutex
+	defaultLevel = zapcore.InfoLevel
+	overrides    = make(map[string]*override)
+	// effective is a copy of the levels in force, read on every log call
+	effective atomic.Pointer[map[string]zapcore.Level]
+	audit     *zap.SugaredLogger
+)
+
+func init() {
+	publish()
+}
+
+// Init puts the log level of the global logger under the control of this
+// package, so that it can be changed at runtime. It must be called right after
+// logger.Init, before any logger instance is retrieved.
+func Init(development bool) {
+	mu.Lock()
+	if development {
+		defaultLevel = zapcore.DebugLevel
+	}
+	publish()
+	mu.Unlock()
+
+	l := logger.Logger()
+	l.Logger = *l.Logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		// Audit entries bypass the runtime level, so that they can't be turned off
+		audit = zap.New(core).Sugar().With("audit", true)
+		return &levelCore{Core: core}
+	}))
+}
+
+// ParseLevel parses a level name, such as "debug" or "error"
+func ParseLevel(text string) (zapcore.Level, error) {
+	var level zapcore.Level
+	if text == "" {
+		return level, errors.New("log level must not be empty")
+	}
+	err := level.UnmarshalText([]byte(text))
+	return level, err
+}
+
+// GetLevels returns the global log level and the per logger overrides
+func GetLevels() Levels {
+	mu.Lock()
+	defer mu.Unlock()
+
+	levels := Levels{Global: LevelState{Level: defaultLevel.String()}, Loggers: []LevelState{}}
+	for name, o := range overrides {
+		state := LevelState{Logger: name, Level: o.level.String()}
+		if !o.expiresAt.IsZero() {
+			expiresAt := o.expiresAt
+			state.ExpiresAt = &expiresAt
+		}
+		if name == "" {
+			levels.Global = state
+		} else {
+			levels.Loggers = append(levels.Loggers, state)
+		}
+	}
+	sort.Slice(levels.Loggers, func(i, j int) bool { return levels.Loggers[i].Logger < levels.Loggers[j].Logger })
+	return levels
+}
+
+// SetLevel sets the log level of the logger named `name`, or the global level if
+// `name` is empty. If `ttl` is positive, the previous level is restored once it
+// has passed.
+func SetLevel(name string, level zapcore.Level, ttl time.Duration, caller Caller) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	previous := overrides[name]
+	o := &override{level: level, previous: previous}
+	if previous != nil {
+		previous.stop()
+		if !previous.expiresAt.IsZero() {
+			// Never restore a temporary level, restore what it would have reverted to
+			o.previous = previous.previous
+		}
+	}
+	if ttl > 0 {
+		o.expiresAt = time.Now().Add(ttl)
+		o.timer = time.AfterFunc(ttl, func() { expire(name, o) })
+	}
+	auditChange("Log level changed", name, levelFor(name), level, caller, "ttl", ttl.String())
+
+	overrides[name] = o
+	publish()
+}
+
+// ResetLevel removes the log level set for the logger named `name`, so that it
+// follows the global level again. If `name` is empty, the global level is reset
+// to its default.
+func ResetLevel(name string, caller Caller) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	previous := levelFor(name)
+	if o, ok := overrides[name]; ok {
+		o.stop()
+		delete(overrides, name)
+	}
+	auditChange("Log level reset", name, previous, levelFor(name), caller)
+	publish()
+}
+
+func (o *override) stop() {
+	if o.timer != nil {
+		o.timer.Stop()
+	}
+}
+
+// expire restores the level which was in force before `o` was set, unless `o`
+// has been replaced in the meantime
+func expire(name string, o *override) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if overrides[name] != o {
+		return
+	}
+	if o.previous != nil {
+		overrides[name] = o.previous
+	} else {
+		delete(overrides, name)
+	}
+	auditChange("Log level reverted", name, o.level, levelFor(name), Caller{Protocol: "ttl"})
+	publish()
+}
+
+// Audit writes an entry to the audit log, recording an administrative action
+// taken by `caller`. Audit entries are written regardless of the log level.
+func Audit(msg string, caller Caller, keysAndValues ...interface{}) {
+	if audit == nil {
+		return
+	}
+	audit.Infow(msg, append([]interface{}{"caller", caller}, keysAndValues...)...)
+}
+
+func auditChange(msg string, name string, from, to zapcore.Level, caller Caller, keysAndValues ...interface{}) {
+	if name == "" {
+		name = "global"
+	}
+	Audit(msg, caller, append([]interface{}{"logger", name, "previous_level", from.String(), "level", to.String()}, keysAndValues...)...)
+}
+
+// levelFor returns the level in force for the logger named `name`. Must be
+// called with mu held.
+func levelFor(name string) zapcore.Level {
+	if o, ok := overrides[name]; ok {
+		return o.level
+	}
+	if o, ok := overrides[""]; ok {
+		return o.level
+	}
+	return defaultLevel
+}
+
+// publish makes the current levels visible to the loggers. Must be called with
+// mu held.
+func publish() {
+	levels := map[string]zapcore.Level{"": defaultLevel}
+	for name, o := range overrides {
+		levels[name] = o.level
+	}
+	effective.Store(&levels)
+}
+
+func enabled(name string, lvl zapcore.Level) bool {
+	levels := *effective.Load()
+	if level, ok := levels[name]; ok && name != "" {
+		return level.Enabled(lvl)
+	}
+	return levels[""].Enabled(lvl)
+}
+
+// levelCore filters entries by the runtime log level instead of the level the
+// wrapped core was built with, so that the level can be lowered below it. The
+// logger name is picked up from the LoggerNameKey field.
+type levelCore struct {
+	zapcore.Core
+	name string
+}
+
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	return enabled(c.name, lvl)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	name := c.name
+	for _, field := range fields {
+		if field.Key == LoggerNameKey && field.Type == zapcore.StringType {
+			name = field.String
+		}
+	}
+	return &levelCore{Core: c.Core.With(fields), name: name}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !enabled(c.name, ent.Level) {
+		return ce
+	}
+	if c.Core.Enabled(ent.Level) {
+		// Keeps the behaviour of the wrapped core, such as sampling
+		return c.Core.Check(ent, ce)
+	}
+	return ce.AddCore(ent, c.Core)
+}
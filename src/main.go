@@ -8,12 +8,16 @@ import (
 	"time"
 
 	"github.com/coderollers/go-logger"
-	"github.com/coderollers/go-stats/concurrency"
 	"github.com/spf13/pflag"
 
 	"my-microservice/api"
 	"my-microservice/configuration"
 	"my-microservice/docs"
+	"my-microservice/health"
+	"my-microservice/jobs"
+	"my-microservice/lifecycle"
+	"my-microservice/logging"
+	"my-microservice/tasks"
 )
 
 func main() {
@@ -28,21 +32,26 @@ func main() {
 
 	// Configure command-line parameters
 	pflag.Int32VarP(&appConfig.CleanupTimeoutSec, "timeout", "t", appConfig.CleanupTimeoutSec, "Time to wait for graceful shutdown on SIGTERM/SIGINT in seconds. Default: 300")
+	pflag.Int32Var(&appConfig.DrainPropagationDelaySec, "drain-delay", appConfig.DrainPropagationDelaySec, "Time to keep serving after being marked not-ready on SIGTERM/SIGINT in seconds. Default: 5")
 	pflag.Int32Var(&appConfig.HttpPort, "http-port", appConfig.HttpPort, "TCP port for the HTTP listener to bind to. Default: 8080")
 	pflag.Int32Var(&appConfig.GrpcPort, "grpc-port", appConfig.GrpcPort, "TCP port for the GRPC listener to bind to. If this matches http-port, GRPC-Web will be enabled. Default: 9000")
+	pflag.Int32Var(&appConfig.Management.Port, "management-port", appConfig.Management.Port, "TCP port for the management listener to bind to. Set to 0 to disable it. Default: 8081")
 	pflag.BoolVarP(&appConfig.UseSwagger, "swagger", "s", false, "Activate swagger. Do not use this in Production!")
 	pflag.BoolVarP(&appConfig.Development, "devel", "d", false, "Start in development mode. Implies --swagger. Do not use this in Production!")
 	pflag.BoolVarP(&appConfig.GinLogger, "gin-logger", "g", false, "Activate Gin's logger, for debugging. Do not use this in Production!")
 	pflag.Parse()
 
-	// Initialize main context and set up cancellation token for SIGINT/SIGQUIT
+	// Initialize main context and set up cancellation token for SIGINT/SIGTERM
 	ctx = context.Background()
 	ctx, cancel = context.WithCancel(ctx)
-	cSignal := make(chan os.Signal)
+	cSignal := make(chan os.Signal, 1)
 	signal.Notify(cSignal, os.Interrupt, syscall.SIGTERM)
+	cQuit := make(chan os.Signal, 1)
+	signal.Notify(cQuit, syscall.SIGQUIT)
 
 	// Initialize logger
-	logger.Init(ctx, true, appConfig.Development)
+	logger.Init(ctx, false, appConfig.Development)
+	logging.Init(appConfig.Development)
 	logger.SetCorrelationIdFieldKey(configuration.CorrelationIdKey)
 	logger.SetCorrelationIdContextKey(configuration.CorrelationIdKey)
 	log := logger.SugaredLogger()
@@ -64,9 +73,37 @@ func main() {
 			log.Fatalf("GRPC port is %d but must be higher than 1024 and lower than 65000 for production mode!", appConfig.GrpcPort)
 		}
 
+		if appConfig.Management.Port != 0 && (appConfig.Management.Port <= 1024 || appConfig.Management.Port >= 65000) {
+			log.Fatalf("Management port is %d but must be higher than 1024 and lower than 65000 for production mode!", appConfig.Management.Port)
+		}
+
+		if appConfig.Management.Port != 0 && appConfig.Management.Token == "" && !appConfig.Management.Loopback() {
+			log.Fatalf("Management listener is bound to %q but MANAGEMENT_TOKEN is not set, set it or bind to a loopback address for production mode!", appConfig.Management.BindAddress)
+		}
+
+		if appConfig.AccessLog.GrpcLogPayloads {
+			log.Warnf("GRPC payload logging is only available in development mode and will be ignored!")
+		}
+
+		if appConfig.Pagination.TokenKey == "" {
+			log.Warnf("PAGE_TOKEN_KEY is not set, page tokens will only be valid on this replica until it restarts!")
+		}
+
 		// TEMPLATE: Add more sanity checks here
 	}
 
+	if err := appConfig.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %s", err.Error())
+	}
+
+	if appConfig.HttpServer.WriteTimeoutSec > 0 && appConfig.HttpServer.WriteTimeoutSec*1000 <= appConfig.Timeouts.DefaultRequestTimeoutMs {
+		log.Warnf("HTTP write timeout (%d seconds) is not longer than the default request timeout (%d ms), slow requests will be cut off without a response!", appConfig.HttpServer.WriteTimeoutSec, appConfig.Timeouts.DefaultRequestTimeoutMs)
+	}
+
+	if appConfig.HttpPort == appConfig.GrpcPort && appConfig.HttpServer.WriteTimeoutSec > 0 {
+		log.Warnf("HTTP write timeout (%d seconds) also applies to the GRPC streams served on the HTTP port, set HTTP_WRITE_TIMEOUT_SEC to 0 to serve longer streams!", appConfig.HttpServer.WriteTimeoutSec)
+	}
+
 	if appConfig.Development {
 		appConfig.UseSwagger = true
 	}
@@ -81,36 +118,94 @@ func main() {
 		docs.SwaggerInfo.Description = appConfig.Swagger.Description
 	}
 	log.Infof(docs.SwaggerInfo.BasePath)
+	log.Infow("Effective configuration", "configuration", appConfig)
 
 	// TEMPLATE: Further initialization goes here (kms, database, etc)
 
-	// Trigger context cancellation token on SIGINT/SIGTERM
+	// Trigger context cancellation token on SIGINT/SIGTERM, force exit on the second one
 	go func() {
 		<-cSignal
-		log.Warnf("SIGTERM received, attempting graceful exit.")
+		log.Warnf("SIGTERM received, attempting graceful exit. Send it again to force exit.")
 		cancel()
+		<-cSignal
+		log.Errorf("Second termination signal received, forcing exit.")
+		_ = log.Sync()
+		os.Exit(1)
+	}()
+
+	// Dump goroutine stacks on SIGQUIT without exiting
+	go func() {
+		for range cQuit {
+			log.Warnf("SIGQUIT received, goroutine stacks:\n%s", tasks.GoroutineDump())
+		}
 	}()
 
-	// Start the API HTTP Server
+	// Register the components in the lifecycle manager. They are started in
+	// dependency order and stopped in reverse order.
 	log.Info("Starting webapi handler")
 	ginRouter := api.SetupGin()
-	grpcServer, grpcWebWrapper := api.StartGrpc(ctx)
+	grpcServer, grpcWebWrapper := api.SetupGrpc()
+
+	components := lifecycle.New(time.Duration(appConfig.CleanupTimeoutSec) * time.Second)
+	// Registered first, so that the probes are served while the other components start
+	if appConfig.Management.Port != 0 {
+		managementRouter, err := api.SetupManagementGin()
+		if err != nil {
+			log.Fatalf("Cannot set up the management router: %s", err.Error())
+		}
+		mustRegister(components, api.ManagementServerComponent(managementRouter, api.SetupManagementGrpc()))
+	}
+	mustRegister(components, api.TelemetryComponent())
+	// TEMPLATE: Register further components here (database pools, workers, etc)
+	mustRegister(components, jobs.Component(jobs.NewMemoryStore(time.Duration(appConfig.Jobs.RetentionSec)*time.Second)))
+	if grpcWebWrapper == nil {
+		mustRegister(components, api.GrpcComponent(grpcServer))
+	}
+	mustRegister(components, api.HttpServerComponent(ginRouter, grpcServer, grpcWebWrapper))
 
-	go api.StartHttpServer(ctx, ginRouter, grpcServer, grpcWebWrapper)
+	if err := components.Start(ctx); err != nil {
+		log.Fatalf("Startup failed: %s", err.Error())
+	}
+	if appConfig.Maintenance.Enabled {
+		log.Warnf("Starting in maintenance mode, requests will be rejected until it is turned off")
+		health.SetMaintenance(true)
+	}
+	health.SetReady(true)
 
 	// Block until cancellation signal is received
 	<-ctx.Done()
 
+	// Stop receiving new traffic, while still serving until the orchestrator has noticed
+	health.SetReady(false)
+	if appConfig.DrainPropagationDelaySec > 0 {
+		log.Infof("Marked not-ready. Waiting %d seconds for the change to propagate before draining.", appConfig.DrainPropagationDelaySec)
+		time.Sleep(time.Duration(appConfig.DrainPropagationDelaySec) * time.Second)
+	}
+
 	// Clean up and attempt graceful exit
 	log.Infof("Graceful shutdown initiated. Waiting for %d seconds before forced exit.", appConfig.CleanupTimeoutSec)
 	ctx, cancel = context.WithTimeout(context.Background(), time.Second*time.Duration(appConfig.CleanupTimeoutSec))
-	go func() {
-		// Eventual clean-up logic would go in this block
-		concurrency.GlobalWaitGroup.Wait()
+	defer cancel()
+	if err := components.Stop(ctx); err != nil {
+		log.Errorf("Graceful shutdown failed: %s", err.Error())
+	}
+
+	// Wait for background tasks and report the ones which did not finish in time
+	if err := tasks.Wait(ctx); err != nil {
+		for _, task := range tasks.List() {
+			log.Errorw("Task still running at shutdown", "task", task.Name, "task_id", task.Id, "running_for", time.Since(task.Started).String(), configuration.CorrelationIdKey, task.CorrelationId)
+		}
+		if appConfig.Development {
+			log.Errorf("Goroutine stacks at shutdown:\n%s", tasks.GoroutineDump())
+		}
+	} else {
 		log.Infof("Cleanup done.")
-		cancel()
-	}()
-	<-ctx.Done()
+	}
 	log.Info("Exiting.")
+}
 
+func mustRegister(components *lifecycle.Manager, component lifecycle.Component) {
+	if err := components.Register(component); err != nil {
+		logger.SugaredLogger().Fatalf("Cannot register component: %s", err.Error())
+	}
 }
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"my-microservice/configuration"
+)
+
+// Concurrency limiter metrics, labelled by limiter name
+var (
+	LimiterLimit = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: configuration.MetricsNamespace,
+		Subsystem: "limiter",
+		Name:      "limit",
+		Help:      "Current concurrency limit.",
+	}, []string{"limiter"})
+	LimiterInflight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: configuration.MetricsNamespace,
+		Subsystem: "limiter",
+		Name:      "inflight",
+		Help:      "Number of requests currently being processed.",
+	}, []string{"limiter"})
+	LimiterShed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: configuration.MetricsNamespace,
+		Subsystem: "limiter",
+		Name:      "shed_total",
+		Help:      "Number of requests rejected because the concurrency limit was reached.",
+	}, []string{"limiter"})
+)
+
+// Asynchronous job metrics
+var (
+	JobsQueued = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: configuration.MetricsNamespace,
+		Subsystem: "jobs",
+		Name:      "queued",
+		Help:      "Number of jobs waiting for a worker.",
+	})
+	JobsRunning = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: configuration.MetricsNamespace,
+		Subsystem: "jobs",
+		Name:      "running",
+		Help:      "Number of jobs currently running.",
+	})
+	JobsFinished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: configuration.MetricsNamespace,
+		Subsystem: "jobs",
+		Name:      "finished_total",
+		Help:      "Number of jobs finished, by job name and final state.",
+	}, []string{"name", "state"})
+)
+
+// Runtime state metrics
+var (
+	MaintenanceMode = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: configuration.MetricsNamespace,
+		Name:      "maintenance_mode",
+		Help:      "Whether the maintenance mode is active (1) or not (0).",
+	})
+	PanicsRecovered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: configuration.MetricsNamespace,
+		Name:      "panics_recovered_total",
+		Help:      "Number of panics recovered while serving requests, by transport.",
+	}, []string{"transport"})
+	// TEMPLATE: Add more metrics here
+)
+
+// Handler returns the HTTP handler serving all registered metrics in the
+// Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
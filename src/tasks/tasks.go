@@ -0,0 +1,126 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"my-microservice/configuration"
+)
+
+// Task describes a background task or in-flight request
+type Task struct {
+	Id            uint64    `json:"id"`
+	Name          string    `json:"name"`
+	Started       time.Time `json:"started"`
+	CorrelationId string    `json:"correlation_id,omitempty"`
+}
+
+var (
+	mu     sync.Mutex
+	nextId uint64
+	active = make(map[uint64]Task)
+	wg     sync.WaitGroup
+)
+
+// Track records a task named `name` until the returned function is called. The
+// correlation ID is taken from `ctx`, which can also be a *gin.Context. The
+// calling goroutine is labelled with the task, so that it can be identified in
+// goroutine dumps.
+//
+//	done := tasks.Track(c, "IndexGet")
+//	defer done()
+func Track(ctx context.Context, name string) (done func()) {
+	task := register(ctx, name)
+	pprof.SetGoroutineLabels(withLabels(ctx, task))
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			// Restores the labels of the caller, which are inherited from ctx
+			pprof.SetGoroutineLabels(ctx)
+			finish(task)
+		})
+	}
+}
+
+// Go runs fn in a new goroutine, tracked as a task named `name`. The task is
+// registered before the goroutine starts, so it can't be missed by Wait.
+func Go(ctx context.Context, name string, fn func(ctx context.Context)) {
+	task := register(ctx, name)
+	go func() {
+		defer finish(task)
+		pprof.Do(ctx, taskLabels(task), fn)
+	}()
+}
+
+func register(ctx context.Context, name string) Task {
+	correlationId, _ := ctx.Value(configuration.CorrelationIdKey).(string)
+
+	mu.Lock()
+	defer mu.Unlock()
+	nextId++
+	task := Task{Id: nextId, Name: name, Started: time.Now(), CorrelationId: correlationId}
+	active[task.Id] = task
+	wg.Add(1)
+	return task
+}
+
+func finish(task Task) {
+	mu.Lock()
+	delete(active, task.Id)
+	mu.Unlock()
+	wg.Done()
+}
+
+func taskLabels(task Task) pprof.LabelSet {
+	return pprof.Labels(
+		"task", task.Name,
+		"task_id", strconv.FormatUint(task.Id, 10),
+		configuration.CorrelationIdKey, task.CorrelationId,
+	)
+}
+
+func withLabels(ctx context.Context, task Task) context.Context {
+	return pprof.WithLabels(ctx, taskLabels(task))
+}
+
+// List returns the tasks which are currently running, oldest first
+func List() []Task {
+	mu.Lock()
+	list := make([]Task, 0, len(active))
+	for _, task := range active {
+		list = append(list, task)
+	}
+	mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Id < list[j].Id })
+	return list
+}
+
+// Wait blocks until all tasks are done or ctx expires
+func Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GoroutineDump returns the stacks of all goroutines, grouped by stack and
+// annotated with the task labels set by Track.
+func GoroutineDump() string {
+	var buf bytes.Buffer
+	_ = pprof.Lookup("goroutine").WriteTo(&buf, 1)
+	return buf.String()
+}